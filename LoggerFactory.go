@@ -2,51 +2,330 @@ package common_logger
 
 import (
 	"errors"
+	"fmt"
+	"reflect"
 	"sync"
+	"sync/atomic"
 
 	"github.com/vlbarou/logger/default_logger"
+	"github.com/vlbarou/logger/logapi"
 )
 
 var (
-	// assign a default logger, in case `createLogger` is not explicitly called (e.g. in unit tests)
-	loggerInstance Logger
+	// until GetLogger is called, the records are buffered, to be replayed into the global logger once it is created
+	preInit        = &preInitBuffer{}
+	loggerInstance atomic.Pointer[Logger] // the global logger, read by the package functions while GetLogger may replace it
 	once           sync.Once
 	initError      error
+	initType       LoggerType
+	initConfig     []Configuration // configuration of the first call, to detect the conflicting calls
 )
 
-func GetLogger(loggerType LoggerType, config ...Config) (Logger, error) {
+func init() {
+	setInstance(preInit.logger())
+}
+
+// instance returns the global logger
+func instance() Logger {
+	return *loggerInstance.Load()
+}
+
+// setInstance replaces the global logger
+func setInstance(l Logger) {
+	loggerInstance.Store(&l)
+}
+
+// ErrConflictingConfig is returned when GetLogger is called again with a different logger type or configuration.
+// The global logger is created only once, so the new configuration is ignored; use NewLogger for additional loggers.
+var ErrConflictingConfig = errors.New("the global logger is already initialized with a different configuration")
+
+// GetLogger creates the global logger of the given backend, once. The configuration is either a Config or a ConfigV2;
+// if it is invalid, the default logger is initialized and the validation errors are returned.
+// Later calls return the same logger, along with ErrConflictingConfig if they ask for a different one.
+func GetLogger(loggerType LoggerType, config ...Configuration) (Logger, error) {
+	initialized := true
 	once.Do(func() {
-		switch loggerType {
-		case Zap:
-			loggerInstance = startLogger(config)
-		default:
-			loggerInstance = default_logger.New()
-			initError = errors.New("required logger not found. Default logger initialized")
-		}
+		initialized = false
+		initType, initConfig = loggerType, config
+		var l Logger
+		l, initError = newLogger(loggerType, config)
+		setGlobalConfig(config)
+		setInstance(l)
+		preInit.replay(l)
 	})
-	return loggerInstance, initError
+
+	if initialized && conflicts(loggerType, config) {
+		return instance(), fmt.Errorf("%w: requested %s logger", ErrConflictingConfig, loggerType)
+	}
+	return instance(), initError
+}
+
+// GetLoggerByName is like GetLogger, with the backend selected by its registered name (e.g. from configuration)
+func GetLoggerByName(name string, config ...Configuration) (Logger, error) {
+	loggerType, err := ParseLoggerType(name)
+	if err != nil {
+		once.Do(func() {
+			l := defaultLogger(config)
+			initError = fmt.Errorf("%w. Default logger initialized", err)
+			setInstance(l)
+			preInit.replay(l)
+		})
+		return instance(), fmt.Errorf("%w. Default logger initialized", err)
+	}
+	return GetLogger(loggerType, config...)
+}
+
+// NewLogger creates a logger of the registered backend, independent of the global logger and of any other instance.
+// The caller owns it, so it has to shut it down. If several Zap loggers enable their log server (AdminServer),
+// each one needs its own AdminPort, or "0" to choose a free one.
+func NewLogger(loggerType LoggerType, config ...Configuration) (Logger, error) {
+	b, err := lookupBackend(loggerType)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := resolveConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := b.factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s logger: %w", b.name, err)
+	}
+	return l, nil
+}
+
+// newLogger creates a logger with NewLogger, or the default logger if the backend is unknown,
+// the configuration is invalid or the backend fails
+func newLogger(loggerType LoggerType, config []Configuration) (Logger, error) {
+	l, err := NewLogger(loggerType, config...)
+	if err != nil {
+		return defaultLogger(config), fmt.Errorf("%w. Default logger initialized", err)
+	}
+	return l, nil
+}
+
+// defaultLogger returns the default logger, which still redacts, collapses the duplicates and exits as configured
+// when the configuration can be resolved. If the redaction is invalid, the usual credentials are redacted.
+func defaultLogger(config []Configuration) *default_logger.DefaultLogger {
+	l := default_logger.New()
+	if len(config) == 0 {
+		return l
+	}
+	cfg, err := config[0].Resolve()
+	if err != nil {
+		return l
+	}
+
+	redactor, err := logapi.NewRedactor(cfg.Redaction)
+	if err != nil {
+		redactor, _ = logapi.NewRedactor(logapi.DefaultRedaction())
+	}
+	l.WithRedactor(redactor)
+	if cfg.DedupWindow > 0 {
+		l.WithDedup(cfg.DedupWindow)
+	}
+	if cfg.ExitFunc != nil {
+		l.WithExitFunc(cfg.ExitFunc)
+	}
+	return l
+}
+
+// resolveConfig returns the validated configuration, or the default one if none is given
+func resolveConfig(config []Configuration) (ConfigV2, error) {
+	if len(config) == 0 {
+		return DefaultConfigV2(), nil
+	}
+
+	cfg, err := config[0].Resolve()
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		return ConfigV2{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// conflicts reports whether a later call of GetLogger asks for a different logger than the first one.
+// Calls without configuration just ask for the global logger.
+func conflicts(loggerType LoggerType, config []Configuration) bool {
+	if loggerType != initType {
+		return true
+	}
+	if len(config) == 0 {
+		return false
+	}
+
+	requested, err := resolveConfig(config)
+	if err != nil {
+		return true
+	}
+	initial, err := resolveConfig(initConfig)
+	if err != nil || !sameFunc(requested.ExitFunc, initial.ExitFunc) ||
+		!samePatterns(requested.Redaction.Patterns, initial.Redaction.Patterns) {
+		return true
+	}
+	// functions are never deeply equal, unless nil, so the functions and the patterns (see ValuePattern.Valid) are compared above
+	requested.ExitFunc, initial.ExitFunc = nil, nil
+	requested.Redaction.Patterns, initial.Redaction.Patterns = nil, nil
+	return !reflect.DeepEqual(requested, initial)
+}
+
+// sameFunc reports whether both functions are nil or the same function
+func sameFunc[F any](a, b F) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
+
+// samePatterns reports whether the value patterns have the same names, regexps and validations
+func samePatterns(a, b []logapi.ValuePattern) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || (a[i].Regexp == nil) != (b[i].Regexp == nil) || !sameFunc(a[i].Valid, b[i].Valid) {
+			return false
+		}
+		if a[i].Regexp != nil && a[i].Regexp.String() != b[i].Regexp.String() {
+			return false
+		}
+	}
+	return true
 }
 
 func Shutdown() error {
-	return loggerInstance.Shutdown()
+	return instance().Shutdown()
+}
+
+// With returns a child of the global logger that adds the given key-value pairs to every record
+func With(args ...any) Logger {
+	return instance().With(args...)
+}
+
+// Named returns a named child of the global logger, e.g. Named("db")
+func Named(name string) Logger {
+	return instance().Named(name)
+}
+
+// WithCallerSkip returns a child of the global logger that skips the given number of extra stack frames
+// to report the caller, for applications that wrap the logger in their own functions
+func WithCallerSkip(skip int) Logger {
+	return logapi.WithCallerSkip(instance(), skip)
+}
+
+// global returns the global logger, skipping the frame of the package function to report the caller.
+// It is cached as long as the global logger stays the same.
+func global() Logger {
+	l := instance()
+	if cached := globalSkipped.Load(); cached != nil && cached.base == l {
+		return cached.skipped
+	}
+
+	cached := &skippedLogger{base: l, skipped: logapi.WithCallerSkip(l, 1)}
+	globalSkipped.Store(cached)
+	return cached.skipped
+}
+
+// skippedLogger is the global logger along with its child used by the package functions
+type skippedLogger struct {
+	base    Logger
+	skipped Logger
+}
+
+var globalSkipped atomic.Pointer[skippedLogger]
+
+// Trace logs a trace-level message
+func Trace(msg string, fields ...any) {
+	global().Trace(msg, fields...)
 }
 
 // Info logs an info-level message
 func Info(msg string, fields ...any) {
-	loggerInstance.Info(msg, fields...)
+	global().Info(msg, fields...)
 }
 
 // Debug logs an debug-level message
 func Debug(msg string, fields ...any) {
-	loggerInstance.Debug(msg, fields...)
+	global().Debug(msg, fields...)
 }
 
 // Warn logs an error-level message
 func Warn(msg string, fields ...any) {
-	loggerInstance.Warn(msg, fields...)
+	global().Warn(msg, fields...)
 }
 
 // Error logs an error-level message
 func Error(msg string, fields ...any) {
-	loggerInstance.Error(msg, fields...)
+	global().Error(msg, fields...)
+}
+
+// DPanic logs a dpanic-level message, and panics in development mode
+func DPanic(msg string, fields ...any) {
+	global().DPanic(msg, fields...)
+}
+
+// Panic logs a panic-level message, then panics
+func Panic(msg string, fields ...any) {
+	global().Panic(msg, fields...)
+}
+
+// Fatal logs a fatal-level message, flushes the outputs, then exits (see ConfigV2.ExitFunc)
+func Fatal(msg string, fields ...any) {
+	global().Fatal(msg, fields...)
+}
+
+// Tracef logs a trace-level message formatted with fmt.Sprintf, if the level is enabled
+func Tracef(format string, args ...any) {
+	global().Tracef(format, args...)
+}
+
+// Debugf logs a debug-level message formatted with fmt.Sprintf, if the level is enabled
+func Debugf(format string, args ...any) {
+	global().Debugf(format, args...)
+}
+
+// Infof logs a info-level message formatted with fmt.Sprintf, if the level is enabled
+func Infof(format string, args ...any) {
+	global().Infof(format, args...)
+}
+
+// Warnf logs a warn-level message formatted with fmt.Sprintf, if the level is enabled
+func Warnf(format string, args ...any) {
+	global().Warnf(format, args...)
+}
+
+// Errorf logs a error-level message formatted with fmt.Sprintf, if the level is enabled
+func Errorf(format string, args ...any) {
+	global().Errorf(format, args...)
+}
+
+// TraceFn logs the trace-level message built by fn, which is only called if the level is enabled
+func TraceFn(fn MessageFunc) {
+	global().TraceFn(fn)
+}
+
+// DebugFn logs the debug-level message built by fn, which is only called if the level is enabled
+func DebugFn(fn MessageFunc) {
+	global().DebugFn(fn)
+}
+
+// InfoFn logs the info-level message built by fn, which is only called if the level is enabled
+func InfoFn(fn MessageFunc) {
+	global().InfoFn(fn)
+}
+
+// WarnFn logs the warn-level message built by fn, which is only called if the level is enabled
+func WarnFn(fn MessageFunc) {
+	global().WarnFn(fn)
+}
+
+// ErrorFn logs the error-level message built by fn, which is only called if the level is enabled
+func ErrorFn(fn MessageFunc) {
+	global().ErrorFn(fn)
+}
+
+// Enabled reports whether the global logger logs the records of the given level
+func Enabled(level Level) bool {
+	return instance().Enabled(level)
 }
@@ -1,6 +1,9 @@
 package common_logger
 
 import (
+	"bytes"
+	"io"
+	"log/slog"
 	"os"
 	"testing"
 	"time"
@@ -34,12 +37,12 @@ func (suite *LoggerTestSuite) TestCreateLoggerWithDefaultConfig() {
 	_, err = GetLogger(Zap, Config{LogFile: suite.tempLogFile.Name()})
 
 	// assert
-	l, ok := loggerInstance.(*zapLogger.LoggerImpl)
+	l, ok := instance().(*zapLogger.LoggerImpl)
 
 	assert.Nil(suite.T(), err)
 	assert.True(suite.T(), ok)
 	assert.Nil(suite.T(), err)
-	assert.NotNil(suite.T(), loggerInstance)
+	assert.NotNil(suite.T(), instance())
 	assert.NotNil(suite.T(), l)
 
 	maxAge, _ := getLoggerFieldValue(l, MaxAge).(int)
@@ -72,12 +75,12 @@ func (suite *LoggerTestSuite) TestCreateLoggerWithCustomConfig() {
 	_, err = GetLogger(Zap, config)
 
 	// assert
-	l, ok := loggerInstance.(*zapLogger.LoggerImpl)
+	l, ok := instance().(*zapLogger.LoggerImpl)
 
 	assert.Nil(suite.T(), err)
 	assert.True(suite.T(), ok)
 	assert.Nil(suite.T(), err)
-	assert.NotNil(suite.T(), loggerInstance)
+	assert.NotNil(suite.T(), instance())
 	assert.NotNil(suite.T(), l)
 
 	logRotationEnabled, _ := getLoggerFieldValue(l, LogRotationEnabled).(bool)
@@ -114,14 +117,17 @@ func (suite *LoggerTestSuite) TestCallGetLoggerMultipleTimes() {
 	_, err = GetLogger(Zap, config)
 
 	// assert
-	l, ok := loggerInstance.(*zapLogger.LoggerImpl)
+	l, ok := instance().(*zapLogger.LoggerImpl)
 
-	assert.Nil(suite.T(), err)
+	assert.ErrorIs(suite.T(), err, ErrConflictingConfig)
 	assert.True(suite.T(), ok)
-	assert.Nil(suite.T(), err)
-	assert.NotNil(suite.T(), loggerInstance)
+	assert.NotNil(suite.T(), instance())
 	assert.NotNil(suite.T(), l)
 
+	// asking for the global logger without configuration is not a conflict
+	_, err = GetLogger(Zap)
+	assert.Nil(suite.T(), err)
+
 	logRotationEnabled, _ := getLoggerFieldValue(l, LogRotationEnabled).(bool)
 	assert.False(suite.T(), logRotationEnabled)
 	maxAge, _ := getLoggerFieldValue(l, MaxAge).(int)
@@ -134,6 +140,82 @@ func (suite *LoggerTestSuite) TestCallGetLoggerMultipleTimes() {
 	assert.Equal(suite.T(), 3, maxBackups)
 }
 
+func (suite *LoggerTestSuite) TestCreateLoggerKeepingAllBackups() {
+
+	var err error
+
+	// arrange
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	// act
+	_, err = GetLogger(Zap, Config{MaxBackups: "0", MaxAge: "0", LogFile: suite.tempLogFile.Name()})
+	_, invalidErr := NewLogger(Zap, ConfigV2{MaxBackups: 2, UnlimitedBackups: true})
+
+	// assert
+	l, ok := instance().(*zapLogger.LoggerImpl)
+	maxBackups, _ := getLoggerFieldValue(l, MaxBackups).(int)
+	maxAge, _ := getLoggerFieldValue(l, MaxAge).(int)
+
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), 0, maxBackups)
+	assert.Equal(suite.T(), 0, maxAge)
+	assert.ErrorContains(suite.T(), invalidErr, "MaxBackups: must be 0 with UnlimitedBackups, got 2")
+}
+
+func (suite *LoggerTestSuite) TestSameRedactionIsNotAConflict() {
+
+	var err error
+
+	// arrange
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+	config := ConfigV2{LogFile: suite.tempLogFile.Name(), Outputs: []string{OutputFile}, Redaction: DefaultRedaction()}
+	_, err = GetLogger(Zap, config)
+
+	// act
+	_, sameErr := GetLogger(Zap, ConfigV2{LogFile: suite.tempLogFile.Name(), Outputs: []string{OutputFile}, Redaction: DefaultRedaction()})
+	otherPatterns := DefaultRedaction()
+	otherPatterns.Patterns = otherPatterns.Patterns[:2]
+	config.Redaction = otherPatterns
+	_, otherErr := GetLogger(Zap, config)
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), sameErr)
+	assert.ErrorIs(suite.T(), otherErr, ErrConflictingConfig)
+	assert.Nil(suite.T(), Shutdown())
+}
+
+func (suite *LoggerTestSuite) TestDefaultLoggerKeepsTheConfiguredRedaction() {
+
+	// arrange
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	exitCode := -1
+	config := ConfigV2{
+		Outputs:   []string{"syslog"},
+		Redaction: DefaultRedaction(),
+		ExitFunc:  func(code int) { exitCode = code },
+	}
+
+	// act
+	l, initErr := GetLogger(Zap, config)
+	l.Info("login", "password", "secret")
+	l.Fatal("bye")
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.ErrorContains(suite.T(), initErr, "Default logger initialized")
+	assert.Contains(suite.T(), string(out), "password=***")
+	assert.NotContains(suite.T(), string(out), "secret")
+	assert.Equal(suite.T(), 1, exitCode)
+}
+
 func (suite *LoggerTestSuite) TestShutdown() {
 
 	var err error
@@ -157,8 +239,8 @@ func (suite *LoggerTestSuite) TestLogEvents() {
 
 	// arrange
 	suite.tempLogFile, suite.tempDir, err = createTempFile()
-	loggerInstance = new(mocks.Logger)
-	l, ok := loggerInstance.(*mocks.Logger)
+	setInstance(new(mocks.Logger))
+	l, ok := instance().(*mocks.Logger)
 
 	l.On("Info", mock.Anything, mock.Anything)
 	l.On("Debug", mock.Anything, mock.Anything)
@@ -176,6 +258,44 @@ func (suite *LoggerTestSuite) TestLogEvents() {
 
 }
 
+func (suite *LoggerTestSuite) TestWith() {
+
+	// arrange
+	setInstance(new(mocks.Logger))
+	l, _ := instance().(*mocks.Logger)
+	child := new(mocks.Logger)
+
+	l.On("With", "request_id", "42").Return(child)
+	child.On("Info", "test")
+
+	// act
+	With("request_id", "42").Info("test")
+
+	// assert
+	l.AssertExpectations(suite.T())
+	child.AssertExpectations(suite.T())
+}
+
+func (suite *LoggerTestSuite) TestCreateSlogLogger() {
+
+	// arrange
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{AddSource: true})
+
+	// act
+	_, err := GetLogger(Slog, Config{SlogHandler: handler})
+	Info("test", "key", "value")
+	Named("db").With("request_id", "42").Info("child")
+	Debug("disabled")
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), buf.String(), `"msg":"test","key":"value"`)
+	assert.Contains(suite.T(), buf.String(), "LoggerFactory_test.go")
+	assert.Contains(suite.T(), buf.String(), `"msg":"child","request_id":"42","logger":"db"`)
+	assert.NotContains(suite.T(), buf.String(), "disabled")
+}
+
 func TestRouterTestSuite(t *testing.T) {
 	suite.Run(t, new(LoggerTestSuite))
 }
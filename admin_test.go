@@ -0,0 +1,127 @@
+package common_logger
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vlbarou/logger/default_logger"
+	"github.com/vlbarou/logger/zapLogger"
+)
+
+func (suite *LoggerTestSuite) TestAdminServerIsOptIn() {
+
+	var err error
+
+	// arrange
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+	config := ConfigV2{LogFile: suite.tempLogFile.Name(), Outputs: []string{OutputFile}}
+
+	// act
+	_, err = GetLogger(Zap, config)
+	withoutServer := AdminAddr()
+	config.AdminServer, config.AdminPort = true, "0"
+	l, newErr := NewLogger(Zap, config)
+	addr := l.(*zapLogger.LoggerImpl).AdminAddr()
+
+	// the port is already in use
+	config.AdminPort = strconv.Itoa(addr.(*net.TCPAddr).Port)
+	busy, busyErr := NewLogger(Zap, config)
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), withoutServer)
+	assert.Nil(suite.T(), newErr)
+	assert.True(suite.T(), addr.(*net.TCPAddr).IP.IsLoopback())
+	assert.Nil(suite.T(), busy)
+	assert.ErrorContains(suite.T(), busyErr, "failed to start the log server")
+	assert.Nil(suite.T(), l.Shutdown())
+	assert.Nil(suite.T(), Shutdown())
+}
+
+func (suite *LoggerTestSuite) TestGetLoggerFallsBackWhenAdminServerFails() {
+
+	var err error
+
+	// arrange
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+	listener, listenErr := net.Listen("tcp", "127.0.0.1:0")
+	defer listener.Close()
+
+	// act
+	l, err := GetLogger(Zap, ConfigV2{
+		LogFile:     suite.tempLogFile.Name(),
+		Outputs:     []string{OutputFile},
+		AdminServer: true,
+		AdminPort:   strconv.Itoa(listener.Addr().(*net.TCPAddr).Port),
+	})
+
+	// assert
+	_, ok := l.(*default_logger.DefaultLogger)
+	assert.Nil(suite.T(), listenErr)
+	assert.True(suite.T(), ok)
+	assert.ErrorContains(suite.T(), err, "failed to start the log server")
+}
+
+func (suite *LoggerTestSuite) TestGetLoggerFallsBackWhenTheLogFileCannotBeOpened() {
+
+	// act
+	_, newErr := NewLogger(Zap, ConfigV2{LogFile: "/proc/nope/app.log", Outputs: []string{OutputFile}})
+	l, err := GetLogger(Zap, ConfigV2{LogFile: "/proc/nope/app.log", Outputs: []string{OutputFile}})
+
+	// assert
+	_, ok := l.(*default_logger.DefaultLogger)
+	assert.ErrorContains(suite.T(), newErr, "failed to open log file")
+	assert.True(suite.T(), ok)
+	assert.ErrorContains(suite.T(), err, "failed to open log file")
+}
+
+func (suite *LoggerTestSuite) TestAdminMux() {
+
+	var err error
+
+	// arrange
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+	mux := http.NewServeMux()
+
+	// act
+	_, err = GetLogger(Zap, ConfigV2{
+		LogFile:  suite.tempLogFile.Name(),
+		Outputs:  []string{OutputFile},
+		AdminMux: mux,
+		Admin:    AdminConfig{BearerToken: "s3cret"},
+	})
+	anonymous := httptest.NewRecorder()
+	mux.ServeHTTP(anonymous, httptest.NewRequest(http.MethodPost, zapLogger.LogServerURI+"?level=warn", nil))
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, zapLogger.LogServerURI+"?level=warn", nil)
+	request.Header.Set("Authorization", "Bearer s3cret")
+	mux.ServeHTTP(recorder, request)
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), AdminAddr())
+	assert.Equal(suite.T(), http.StatusUnauthorized, anonymous.Code)
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.False(suite.T(), Enabled(InfoLevel))
+	assert.Nil(suite.T(), Shutdown())
+}
+
+func (suite *LoggerTestSuite) TestAdminMuxRequiresAuthentication() {
+
+	// arrange
+	config := DefaultConfigV2()
+	config.AdminMux = http.NewServeMux()
+	config.Admin.Host = "127.0.0.1" // the loopback interface doesn't apply to a mounted log server
+
+	// act
+	err := config.Validate()
+	config.Admin.HMACKey = []byte("key")
+	authenticatedErr := config.Validate()
+
+	// assert
+	assert.ErrorContains(suite.T(), err, "AdminMux: requires Admin.BearerToken or Admin.HMACKey")
+	assert.Nil(suite.T(), authenticatedErr)
+}
@@ -0,0 +1,93 @@
+package common_logger
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vlbarou/logger/default_logger"
+	"github.com/vlbarou/logger/logapi"
+)
+
+func (suite *LoggerTestSuite) TestNormalizeArgs() {
+
+	err := errors.New("failure")
+	for _, test := range []struct {
+		args      []any
+		expected  []any
+		malformed int
+	}{
+		{[]any{"key", 1}, []any{"key", 1}, 0},
+		{[]any{err, "key", 1}, []any{"error", err, "key", 1}, 0},
+		{[]any{slog.Int("key", 1)}, []any{"key", slog.IntValue(1)}, 0},
+		{[]any{"key"}, []any{"!EXTRA", "key"}, 1},
+		{[]any{42, "value"}, []any{"!BADKEY", 42, "!EXTRA", "value"}, 2},
+		{[]any{"key", 1, true, "other", 2}, []any{"key", 1, "!BADKEY", true, "other", 2}, 1},
+	} {
+		normalized, malformed := logapi.NormalizeArgs(test.args)
+		assert.Equal(suite.T(), test.expected, normalized, test.args)
+		assert.Equal(suite.T(), test.malformed, malformed, test.args)
+	}
+}
+
+func (suite *LoggerTestSuite) TestMalformedArgsAreLoggedAndCounted() {
+
+	// arrange
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, nil)
+
+	_, err := GetLogger(Slog, ConfigV2{SlogHandler: handler})
+
+	// act
+	Named("db").Info("odd", "key", "value", "orphan")
+	With(42, "value").Info("bad key")
+
+	// assert
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(lines))
+	assert.Contains(suite.T(), lines[0], `"msg":"odd","logger":"db","key":"value","!EXTRA":"orphan"`)
+	assert.Contains(suite.T(), lines[1], `"msg":"bad key","!BADKEY":42,"!EXTRA":"value"`)
+	assert.Equal(suite.T(), uint64(3), MalformedArgs())
+}
+
+func (suite *LoggerTestSuite) TestStrictModePanicsWithTheCallSite() {
+
+	// arrange
+	var buf bytes.Buffer
+	logger := default_logger.NewWithOutput(&buf)
+
+	SetStrictMode(StrictPanic)
+	defer SetStrictMode(StrictOff)
+
+	// act
+	var malformedErr *logapi.MalformedArgsError
+	callLine := line(3)
+	func() {
+		defer func() { malformedErr, _ = recover().(*logapi.MalformedArgsError) }()
+		logger.Info("odd", "orphan")
+	}()
+
+	// assert
+	assert.NotNil(suite.T(), malformedErr)
+	assert.True(suite.T(), strings.HasSuffix(malformedErr.Caller, strings.TrimSuffix(callLine, `"`)), malformedErr.Caller)
+	assert.Equal(suite.T(), []any{"orphan"}, malformedErr.Args)
+	assert.Equal(suite.T(), uint64(1), logger.MalformedArgs())
+}
+
+func (suite *LoggerTestSuite) TestDefaultLoggerMalformedArgs() {
+
+	// arrange
+	var buf bytes.Buffer
+	logger := default_logger.NewWithOutput(&buf)
+
+	// act
+	logger.Error("failed", errors.New("timeout"), 42)
+
+	// assert
+	assert.Contains(suite.T(), buf.String(), `msg="failed" error=timeout !BADKEY=42`)
+	assert.Equal(suite.T(), uint64(1), logger.MalformedArgs())
+}
@@ -0,0 +1,334 @@
+package common_logger
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/vlbarou/logger/default_logger"
+	"github.com/vlbarou/logger/logapi"
+)
+
+// MaxBufferedRecords is the number of records logged before the initialization of the global logger
+// that are kept to be replayed; the next ones are dropped and counted (see DroppedRecords)
+const MaxBufferedRecords = 1000
+
+// forwardFrames are the frames between the caller and the global logger when a bufferLogger forwards a record:
+// [bufferLogger.Info, bufferLogger.log, logAt]
+const forwardFrames = 3
+
+type bufferedRecord struct {
+	logger  *bufferLogger // the logger the record was logged with, to replay it with the same fields and name
+	level   Level
+	message string
+	args    []any
+	time    time.Time
+}
+
+// preInitBuffer holds the records logged before GetLogger, until they are replayed into the global logger
+type preInitBuffer struct {
+	mu      sync.Mutex
+	records []bufferedRecord
+	dropped int
+	target  Logger // the global logger, once initialized
+}
+
+// bufferLogger is the global logger until GetLogger is called: it buffers the records, and once the global logger
+// is initialized it forwards them to it. Its children (With, Named) forward to the matching children of the global logger.
+type bufferLogger struct {
+	buffer  *preInitBuffer
+	derive  func(Logger) Logger // returns the matching child of the global logger
+	once    sync.Once
+	base    Logger // the matching child of the global logger
+	forward Logger // base, skipping the frames of the bufferLogger to report the caller
+}
+
+// DroppedRecords returns the number of records logged before the initialization of the global logger
+// that were dropped, because the buffer was full
+func DroppedRecords() int {
+	preInit.mu.Lock()
+	defer preInit.mu.Unlock()
+	return preInit.dropped
+}
+
+// logger returns the root logger of the buffer
+func (b *preInitBuffer) logger() *bufferLogger {
+	return &bufferLogger{
+		buffer: b,
+		derive: func(global Logger) Logger { return global },
+	}
+}
+
+// replay logs the buffered records into the target, which receives all the next records
+func (b *preInitBuffer) replay(target Logger) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.target != nil {
+		return
+	}
+
+	for _, r := range b.records {
+		// records above the error level are replayed at the error level, since the panic already happened
+		level, args := r.level, append(r.args[:len(r.args):len(r.args)], "buffered_at", r.time)
+		if level > ErrorLevel {
+			level, args = ErrorLevel, append(args, "buffered_level", r.level.String())
+		}
+		logAt(r.logger.resolve(target), level, r.message, args...)
+	}
+	if b.dropped > 0 {
+		target.Warn("Records logged before the logger initialization were dropped", "dropped", b.dropped, "limit", MaxBufferedRecords)
+	}
+
+	b.records = nil
+	b.target = target
+}
+
+func (l *bufferLogger) log(level Level, message string, args []any) {
+	b := l.buffer
+	b.mu.Lock()
+	if target := b.target; target != nil {
+		b.mu.Unlock()
+		logAt(l.resolve(target), level, message, args...)
+		return
+	}
+	defer b.mu.Unlock()
+
+	if len(b.records) >= MaxBufferedRecords {
+		b.dropped++
+		return
+	}
+	// the caller may reuse its slice once the call returns
+	b.records = append(b.records, bufferedRecord{logger: l, level: level, message: message, args: slices.Clone(args), time: time.Now()})
+}
+
+// target returns the child of the global logger that matches this logger
+func (l *bufferLogger) target(global Logger) Logger {
+	l.once.Do(func() {
+		l.base = l.derive(global)
+		l.forward = logapi.WithCallerSkip(l.base, forwardFrames)
+	})
+	return l.base
+}
+
+// resolve returns the child of the global logger to forward the records of this logger to
+func (l *bufferLogger) resolve(global Logger) Logger {
+	l.target(global)
+	return l.forward
+}
+
+func (l *bufferLogger) Trace(message string, args ...any) {
+	l.log(TraceLevel, message, args)
+}
+
+func (l *bufferLogger) Info(message string, args ...any) {
+	l.log(InfoLevel, message, args)
+}
+
+func (l *bufferLogger) Debug(message string, args ...any) {
+	l.log(DebugLevel, message, args)
+}
+
+func (l *bufferLogger) Error(message string, args ...any) {
+	l.log(ErrorLevel, message, args)
+}
+
+func (l *bufferLogger) Warn(message string, args ...any) {
+	l.log(WarnLevel, message, args)
+}
+
+func (l *bufferLogger) Tracef(format string, args ...any) {
+	if l.Enabled(TraceLevel) {
+		l.log(TraceLevel, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+func (l *bufferLogger) Debugf(format string, args ...any) {
+	if l.Enabled(DebugLevel) {
+		l.log(DebugLevel, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+func (l *bufferLogger) Infof(format string, args ...any) {
+	if l.Enabled(InfoLevel) {
+		l.log(InfoLevel, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+func (l *bufferLogger) Warnf(format string, args ...any) {
+	if l.Enabled(WarnLevel) {
+		l.log(WarnLevel, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+func (l *bufferLogger) Errorf(format string, args ...any) {
+	if l.Enabled(ErrorLevel) {
+		l.log(ErrorLevel, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+func (l *bufferLogger) TraceFn(fn MessageFunc) {
+	if l.Enabled(TraceLevel) {
+		message, args := fn()
+		l.log(TraceLevel, message, args)
+	}
+}
+
+func (l *bufferLogger) DebugFn(fn MessageFunc) {
+	if l.Enabled(DebugLevel) {
+		message, args := fn()
+		l.log(DebugLevel, message, args)
+	}
+}
+
+func (l *bufferLogger) InfoFn(fn MessageFunc) {
+	if l.Enabled(InfoLevel) {
+		message, args := fn()
+		l.log(InfoLevel, message, args)
+	}
+}
+
+func (l *bufferLogger) WarnFn(fn MessageFunc) {
+	if l.Enabled(WarnLevel) {
+		message, args := fn()
+		l.log(WarnLevel, message, args)
+	}
+}
+
+func (l *bufferLogger) ErrorFn(fn MessageFunc) {
+	if l.Enabled(ErrorLevel) {
+		message, args := fn()
+		l.log(ErrorLevel, message, args)
+	}
+}
+
+func (l *bufferLogger) TraceF(message string, fields ...Field) {
+	if l.Enabled(TraceLevel) {
+		l.log(TraceLevel, message, logapi.FieldArgs(fields))
+	}
+}
+
+func (l *bufferLogger) DebugF(message string, fields ...Field) {
+	if l.Enabled(DebugLevel) {
+		l.log(DebugLevel, message, logapi.FieldArgs(fields))
+	}
+}
+
+func (l *bufferLogger) InfoF(message string, fields ...Field) {
+	if l.Enabled(InfoLevel) {
+		l.log(InfoLevel, message, logapi.FieldArgs(fields))
+	}
+}
+
+func (l *bufferLogger) WarnF(message string, fields ...Field) {
+	if l.Enabled(WarnLevel) {
+		l.log(WarnLevel, message, logapi.FieldArgs(fields))
+	}
+}
+
+func (l *bufferLogger) ErrorF(message string, fields ...Field) {
+	if l.Enabled(ErrorLevel) {
+		l.log(ErrorLevel, message, logapi.FieldArgs(fields))
+	}
+}
+
+// Enabled returns true until the global logger is initialized, since every record is buffered
+func (l *bufferLogger) Enabled(level Level) bool {
+	if target := l.buffer.current(); target != nil {
+		return l.target(target).Enabled(level)
+	}
+	return true
+}
+
+func (l *bufferLogger) DPanic(message string, args ...any) {
+	l.log(DPanicLevel, message, args)
+}
+
+func (l *bufferLogger) Panic(message string, args ...any) {
+	l.log(PanicLevel, message, args)
+	if l.buffer.initialized() {
+		return // the global logger panicked
+	}
+	panic(message)
+}
+
+// Fatal flushes the buffered records to stderr, as the global logger is not initialized yet, before exiting
+func (l *bufferLogger) Fatal(message string, args ...any) {
+	l.buffer.replay(default_logger.NewWithOutput(os.Stderr))
+	l.log(FatalLevel, message, args)
+}
+
+func (l *bufferLogger) With(args ...any) Logger {
+	args = slices.Clone(args) // the child is derived later, once the caller may have reused its slice
+	return &bufferLogger{
+		buffer: l.buffer,
+		derive: func(global Logger) Logger { return l.target(global).With(args...) },
+	}
+}
+
+func (l *bufferLogger) WithCallerSkip(skip int) Logger {
+	return &bufferLogger{
+		buffer: l.buffer,
+		derive: func(global Logger) Logger { return logapi.WithCallerSkip(l.target(global), skip) },
+	}
+}
+
+func (l *bufferLogger) Named(name string) Logger {
+	return &bufferLogger{
+		buffer: l.buffer,
+		derive: func(global Logger) Logger { return l.target(global).Named(name) },
+	}
+}
+
+// MalformedArgs returns the counter of the global logger, once initialized; the buffered records are checked when replayed
+func (l *bufferLogger) MalformedArgs() uint64 {
+	if c, ok := l.buffer.current().(logapi.MalformedArgsCounter); ok {
+		return c.MalformedArgs()
+	}
+	return 0
+}
+
+// Shutdown flushes the buffered records to stderr, as the global logger was never initialized
+func (l *bufferLogger) Shutdown() error {
+	l.buffer.replay(default_logger.NewWithOutput(os.Stderr))
+	return nil
+}
+
+func (l *bufferLogger) Sync() {
+}
+
+// initialized reports whether the records are forwarded to the global logger
+func (b *preInitBuffer) initialized() bool {
+	return b.current() != nil
+}
+
+// current returns the logger the records are forwarded to, or nil until the global logger is initialized
+func (b *preInitBuffer) current() Logger {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.target
+}
+
+// logAt logs the message at the given level
+func logAt(l Logger, level Level, message string, args ...any) {
+	switch level {
+	case TraceLevel:
+		l.Trace(message, args...)
+	case DebugLevel:
+		l.Debug(message, args...)
+	case WarnLevel:
+		l.Warn(message, args...)
+	case ErrorLevel:
+		l.Error(message, args...)
+	case DPanicLevel:
+		l.DPanic(message, args...)
+	case PanicLevel:
+		l.Panic(message, args...)
+	case FatalLevel:
+		l.Fatal(message, args...)
+	default:
+		l.Info(message, args...)
+	}
+}
@@ -0,0 +1,125 @@
+package common_logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *LoggerTestSuite) TestRecordsBeforeInitializationAreReplayed() {
+
+	// arrange
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, nil)
+
+	Info("early", "key", 1)
+	child := Named("db").With("request_id", "42")
+	child.Warn("early child")
+
+	// act
+	_, err := GetLogger(Slog, Config{SlogHandler: handler})
+	child.Info("late child")
+
+	// assert
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 3, len(lines))
+	assert.Contains(suite.T(), lines[0], `"msg":"early","key":1,"buffered_at":`)
+	assert.Contains(suite.T(), lines[1], `"level":"WARN","msg":"early child","request_id":"42","logger":"db","buffered_at":`)
+	assert.Contains(suite.T(), lines[2], `"msg":"late child","request_id":"42","logger":"db"`)
+	assert.Equal(suite.T(), 0, DroppedRecords())
+}
+
+func (suite *LoggerTestSuite) TestRecordsBeyondTheBufferLimitAreDropped() {
+
+	// arrange
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, nil)
+
+	for i := 0; i < MaxBufferedRecords+5; i++ {
+		Info("early", "i", i)
+	}
+
+	// act
+	_, err := GetLogger(Slog, Config{SlogHandler: handler})
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 5, DroppedRecords())
+	assert.Equal(suite.T(), MaxBufferedRecords+1, strings.Count(buf.String(), "\n"))
+	assert.Contains(suite.T(), buf.String(), `"msg":"Records logged before the logger initialization were dropped","dropped":5`)
+}
+
+func (suite *LoggerTestSuite) TestShutdownWithoutInitializationFlushesTheBuffer() {
+
+	// arrange
+	Info("never initialized")
+
+	// act
+	err := Shutdown()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), preInit.records)
+}
+
+func (suite *LoggerTestSuite) TestLoggingWhileInitializing() {
+
+	// arrange
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, nil)
+	started, stop, stopped := make(chan struct{}), make(chan struct{}), make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		Info("concurrent")
+		close(started)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				Info("concurrent")
+			}
+		}
+	}()
+
+	// act
+	<-started
+	_, err := GetLogger(Slog, Config{SlogHandler: handler})
+	close(stop)
+	<-stopped
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), buf.String(), `"msg":"concurrent"`)
+}
+
+func (suite *LoggerTestSuite) TestBufferedRecordsKeepTheirArguments() {
+
+	// arrange
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, nil)
+
+	args := make([]any, 2, 8)
+	args[0], args[1] = "key", 1
+	Info("early", args...)
+	child := With(args...)
+	args[1] = 2 // the caller reuses its slice
+
+	// act
+	_, err := GetLogger(Slog, Config{SlogHandler: handler})
+	child.Info("late child")
+
+	// assert
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(lines))
+	assert.Contains(suite.T(), lines[0], `"msg":"early","key":1,"buffered_at":`)
+	assert.Contains(suite.T(), lines[1], `"msg":"late child","key":1`)
+	assert.Equal(suite.T(), []any{"key", 2, nil, nil}, args[:4]) // the replay doesn't append to the caller's slice
+}
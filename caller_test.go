@@ -0,0 +1,73 @@
+package common_logger
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// line returns the file:line of its caller, offset by the given number of lines
+func line(offset int) string {
+	_, file, l, _ := runtime.Caller(1)
+	return fmt.Sprintf(`%s:%d"`, filepath.Base(file), l+offset)
+}
+
+// logThroughWrapper is a wrapper of the application, which skips its own frame
+func logThroughWrapper(msg string) {
+	WithCallerSkip(1).Info(msg)
+}
+
+func (suite *LoggerTestSuite) TestCallerOfEveryPath() {
+
+	var err error
+
+	// arrange
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	preInitChild := With("key", "value")
+	preInitLine := line(1)
+	preInitChild.Info("pre-init child")
+
+	_, err = GetLogger(Zap, ConfigV2{LogFile: suite.tempLogFile.Name(), Outputs: []string{OutputFile}})
+	logger, _ := GetLogger(Zap)
+
+	// act
+	packageLine := line(1)
+	Info("package function")
+
+	directLine := line(1)
+	logger.Info("direct call")
+
+	childLine := line(1)
+	Named("db").With("key", "value").Info("child logger")
+
+	ctxLine := line(1)
+	InfoCtx(context.Background(), "context function")
+
+	ctxLoggerLine := line(1)
+	InfoCtx(WithContext(context.Background(), logger.Named("ctx")), "context logger")
+
+	wrapperLine := line(1)
+	logThroughWrapper("wrapper")
+
+	forwardLine := line(1)
+	preInitChild.Info("forwarded by pre-init child")
+
+	assert.Nil(suite.T(), Shutdown())
+
+	// assert
+	file := suite.tempLogFile.Name()
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), findLogLine(file, "package function"), packageLine)
+	assert.Contains(suite.T(), findLogLine(file, "direct call"), directLine)
+	assert.Contains(suite.T(), findLogLine(file, "child logger"), childLine)
+	assert.Contains(suite.T(), findLogLine(file, "context function"), ctxLine)
+	assert.Contains(suite.T(), findLogLine(file, "context logger"), ctxLoggerLine)
+	assert.Contains(suite.T(), findLogLine(file, "wrapper"), wrapperLine)
+	assert.Contains(suite.T(), findLogLine(file, "forwarded by pre-init child"), forwardLine)
+	// replayed records are reported where they were replayed, their caller is lost
+	assert.NotContains(suite.T(), findLogLine(file, "pre-init child"), preInitLine)
+}
@@ -0,0 +1,320 @@
+package common_logger
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/vlbarou/logger/logapi"
+	"github.com/vlbarou/logger/zapLogger"
+)
+
+type (
+	// Configuration is implemented by the configurations accepted by GetLogger: the string-based Config and the typed ConfigV2
+	Configuration interface {
+		// Resolve returns the typed configuration, with the defaults applied to the unset fields
+		Resolve() (ConfigV2, error)
+	}
+
+	// ConfigV2 is the typed configuration of the loggers. Zero values select the defaults of DefaultConfigV2.
+	ConfigV2 struct {
+		LogFile          string
+		LogRotation      bool
+		MaxSizeMB        int             // maximum size of a log file before it gets rotated
+		MaxBackups       int             // number of rotated files to keep; 0 selects the default, see UnlimitedBackups
+		MaxAge           time.Duration   // retention of the rotated files, rounded up to whole days; 0 selects the default, see UnlimitedAge
+		UnlimitedBackups bool            // keep all the rotated files; MaxBackups must be 0
+		UnlimitedAge     bool            // never remove the rotated files because of their age; MaxAge must be 0
+		Level            Level           // initial global level
+		Outputs          []string        // any of OutputStdout, OutputStderr and OutputFile
+		SlogHandler      slog.Handler    // handler of the Slog logger type; slog.Default() is used if nil
+		ExitFunc         func(int)       // called by Fatal once the outputs are flushed; os.Exit is used if nil
+		Development      bool            // DPanic panics in development mode
+		Async            AsyncConfig     // asynchronous writes of the Zap logger type, disabled by default
+		Sampling         SamplingConfig  // sampling of the repeated messages of the Zap logger type, disabled by default
+		DedupWindow      time.Duration   // collapses the consecutive duplicates of the Zap logger type logged within the window, see zapLogger.WithDedup
+		Redaction        RedactionConfig // sensitive values redacted by the Zap logger type, none by default (see DefaultRedaction)
+
+		AdminServer bool           // start the log server of the Zap logger type, which is disabled by default
+		AdminPort   string         // port of the log server; "0" chooses a free port, see AdminAddr
+		AdminMux    *http.ServeMux // mount the log server on this mux instead of starting one; requires Admin.BearerToken or Admin.HMACKey, one logger per mux (see zapLogger.LoggerImpl.MountAdmin)
+		Admin       AdminConfig    // bind address (loopback by default), authentication and TLS of the log server
+	}
+
+	// AsyncConfig enables the asynchronous writes: the records are queued and written by a goroutine,
+	// so that a slow output doesn't stall the callers. Shutdown and Sync write the queued records.
+	AsyncConfig struct {
+		QueueSize     int            // number of queued records; 0 writes synchronously
+		Policy        OverflowPolicy // what to do with a record when the queue is full, OverflowBlock by default
+		MinLevel      Level          // with OverflowDropBelowLevel, the records of this level and above are never dropped
+		FlushInterval time.Duration  // how often the outputs are flushed; 0 flushes them as soon as the queue is drained
+	}
+
+	// SamplingConfig limits the records of a repeated message, e.g. the first 10 per second, then every 100th.
+	// The rule of a record is the rule of its named logger, or of the closest ancestor that has one,
+	// else the rule of its level, else the default rule. A rule with a zero Interval doesn't sample the records.
+	// The suppressed records are reported every SummaryInterval, e.g. `suppressed 12345 occurrences of "retrying"`.
+	SamplingConfig struct {
+		Default         SamplingRule
+		Levels          map[Level]SamplingRule
+		Loggers         map[string]SamplingRule
+		SummaryInterval time.Duration // one minute if 0
+	}
+
+	// FieldError reports an invalid configuration field
+	FieldError struct {
+		Field string
+		Err   error
+	}
+)
+
+// The outputs a logger can write to
+const (
+	OutputStdout = zapLogger.OutputStdout
+	OutputStderr = zapLogger.OutputStderr
+	OutputFile   = zapLogger.OutputFile
+)
+
+// OverflowPolicy is what an asynchronous logger does with a record when its queue is full
+type OverflowPolicy = zapLogger.OverflowPolicy
+
+const (
+	OverflowBlock          = zapLogger.OverflowBlock          // the caller waits for room in the queue
+	OverflowDropNewest     = zapLogger.OverflowDropNewest     // the record is dropped
+	OverflowDropOldest     = zapLogger.OverflowDropOldest     // the oldest queued record is dropped to make room
+	OverflowDropBelowLevel = zapLogger.OverflowDropBelowLevel // the record is dropped if its level is below MinLevel, otherwise the caller waits
+)
+
+// SamplingRule logs the First records of a message in each Interval, then every Thereafter-th one
+type SamplingRule = zapLogger.SamplingRule
+
+// Sampler is the algorithm of a sampling rule
+type Sampler = zapLogger.Sampler
+
+const (
+	SamplerPerKey = zapLogger.SamplerPerKey // counts the records of each named logger, level and message exactly
+	SamplerZap    = zapLogger.SamplerZap    // zap's sampler: counts the records by level and hash of the message, in fixed memory
+)
+
+// AdminConfig secures the log server of the Zap logger type: it listens on the loopback interface unless Host is set,
+// and it requires a bearer token or an HMAC signature (see zapLogger.SignAdminRequest) if they are set
+type AdminConfig = zapLogger.AdminSettings
+
+// RedactionConfig selects the sensitive values to redact, by key names or globs and by value patterns
+type RedactionConfig = logapi.RedactionConfig
+
+// Redactable is implemented by the types that know which of their fields are sensitive
+type Redactable = logapi.Redactable
+
+// RedactMode is what the redaction does with a sensitive value
+type RedactMode = logapi.RedactMode
+
+const (
+	RedactMask = logapi.RedactMask // the value is replaced by "***"
+	RedactHash = logapi.RedactHash // the value is replaced by its hash
+	RedactDrop = logapi.RedactDrop // the field is removed
+)
+
+// The built-in value patterns
+var (
+	EmailPattern = logapi.EmailPattern
+	JWTPattern   = logapi.JWTPattern
+	PANPattern   = logapi.PANPattern // card numbers, with a valid Luhn checksum
+)
+
+// DefaultRedaction returns a configuration that masks the usual credentials, and the emails, JWTs and card numbers
+func DefaultRedaction() RedactionConfig {
+	return logapi.DefaultRedaction()
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// DefaultConfigV2 returns the default configuration of the loggers
+func DefaultConfigV2() ConfigV2 {
+	return ConfigV2{
+		LogFile:    zapLogger.LogFile,
+		MaxSizeMB:  zapLogger.MaxSizeMB,
+		MaxBackups: zapLogger.MaxBackups,
+		MaxAge:     zapLogger.MaxAge * 24 * time.Hour,
+		Level:      InfoLevel,
+		Outputs:    []string{OutputStdout, OutputFile},
+		AdminPort:  zapLogger.LoggerServerPort,
+	}
+}
+
+// Resolve returns a copy of the configuration with the defaults applied to the zero-valued fields.
+// MaxBackups and MaxAge 0 select the defaults too, unless UnlimitedBackups and UnlimitedAge are set.
+func (c ConfigV2) Resolve() (ConfigV2, error) {
+	defaults := DefaultConfigV2()
+
+	if c.LogFile == "" {
+		c.LogFile = defaults.LogFile
+	}
+	if c.MaxSizeMB == 0 {
+		c.MaxSizeMB = defaults.MaxSizeMB
+	}
+	if c.MaxBackups == 0 && !c.UnlimitedBackups {
+		c.MaxBackups = defaults.MaxBackups
+	}
+	if c.MaxAge == 0 && !c.UnlimitedAge {
+		c.MaxAge = defaults.MaxAge
+	}
+	if len(c.Outputs) == 0 {
+		c.Outputs = defaults.Outputs
+	}
+	if c.AdminPort == "" {
+		c.AdminPort = defaults.AdminPort
+	}
+	return c, nil
+}
+
+// Validate returns all the invalid fields of the configuration, joined as *FieldError
+func (c ConfigV2) Validate() error {
+	var errs []error
+	invalid := func(field string, format string, args ...any) {
+		errs = append(errs, &FieldError{Field: field, Err: fmt.Errorf(format, args...)})
+	}
+
+	if c.MaxSizeMB < 0 {
+		invalid("MaxSizeMB", "must not be negative, got %d", c.MaxSizeMB)
+	}
+	if c.MaxBackups < 0 {
+		invalid("MaxBackups", "must not be negative, got %d", c.MaxBackups)
+	}
+	if c.MaxAge < 0 {
+		invalid("MaxAge", "must not be negative, got %s", c.MaxAge)
+	}
+	if c.UnlimitedBackups && c.MaxBackups != 0 {
+		invalid("MaxBackups", "must be 0 with UnlimitedBackups, got %d", c.MaxBackups)
+	}
+	if c.UnlimitedAge && c.MaxAge != 0 {
+		invalid("MaxAge", "must be 0 with UnlimitedAge, got %s", c.MaxAge)
+	}
+	if !c.Level.IsValid() {
+		invalid("Level", "unknown level %s", c.Level)
+	}
+	if len(c.Outputs) == 0 {
+		invalid("Outputs", "at least one output is required")
+	}
+	for i, output := range c.Outputs {
+		switch {
+		case output != OutputStdout && output != OutputStderr && output != OutputFile:
+			invalid("Outputs", "unknown output %q, available: %s, %s, %s", output, OutputFile, OutputStderr, OutputStdout)
+		case slices.Contains(c.Outputs[:i], output):
+			invalid("Outputs", "duplicate output %q", output)
+		}
+	}
+	if _, err := strconv.ParseUint(c.AdminPort, 10, 16); c.AdminServer && err != nil {
+		invalid("AdminPort", "invalid port %q", c.AdminPort)
+	}
+	if err := c.Admin.Validate(); c.AdminServer && err != nil {
+		invalid("Admin", "%v", err)
+	}
+	// the mux may be served on any interface, so the loopback default doesn't protect it
+	if c.AdminMux != nil && c.Admin.BearerToken == "" && len(c.Admin.HMACKey) == 0 {
+		invalid("AdminMux", "requires Admin.BearerToken or Admin.HMACKey")
+	}
+	if c.Async.QueueSize < 0 {
+		invalid("Async.QueueSize", "must not be negative, got %d", c.Async.QueueSize)
+	}
+	if !c.Async.Policy.IsValid() {
+		invalid("Async.Policy", "unknown policy %s", c.Async.Policy)
+	}
+	if !c.Async.MinLevel.IsValid() {
+		invalid("Async.MinLevel", "unknown level %s", c.Async.MinLevel)
+	}
+	if c.Async.FlushInterval < 0 {
+		invalid("Async.FlushInterval", "must not be negative, got %s", c.Async.FlushInterval)
+	}
+	c.Sampling.validate(invalid)
+	if c.DedupWindow < 0 {
+		invalid("DedupWindow", "must not be negative, got %s", c.DedupWindow)
+	}
+	if _, err := logapi.NewRedactor(c.Redaction); err != nil {
+		invalid("Redaction", "%v", err)
+	}
+	if c.LogFile == "" && slices.Contains(c.Outputs, OutputFile) {
+		invalid("LogFile", "is required by the %q output", OutputFile)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate reports the invalid rules and summary interval
+func (c SamplingConfig) validate(invalid func(field string, format string, args ...any)) {
+	rule := func(field string, r SamplingRule) {
+		if r.First < 0 {
+			invalid(field+".First", "must not be negative, got %d", r.First)
+		}
+		if r.Thereafter < 0 {
+			invalid(field+".Thereafter", "must not be negative, got %d", r.Thereafter)
+		}
+		if r.Interval < 0 {
+			invalid(field+".Interval", "must not be negative, got %s", r.Interval)
+		}
+		if !r.Sampler.IsValid() {
+			invalid(field+".Sampler", "unknown sampler %s", r.Sampler)
+		}
+	}
+
+	rule("Sampling.Default", c.Default)
+	for level, r := range c.Levels {
+		if !level.IsValid() {
+			invalid("Sampling.Levels", "unknown level %s", level)
+		}
+		rule(fmt.Sprintf("Sampling.Levels[%s]", level), r)
+	}
+	for name, r := range c.Loggers {
+		rule(fmt.Sprintf("Sampling.Loggers[%q]", name), r)
+	}
+	if c.SummaryInterval < 0 {
+		invalid("Sampling.SummaryInterval", "must not be negative, got %s", c.SummaryInterval)
+	}
+}
+
+// maxAgeDays returns MaxAge in whole days, as expected by the log rotation
+func (c ConfigV2) maxAgeDays() int {
+	return int((c.MaxAge + 24*time.Hour - 1) / (24 * time.Hour))
+}
+
+// Resolve converts the string-based configuration to the typed one. Empty numbers select the defaults,
+// while MaxBackups and MaxAge "0" keep all the rotated files, as with the former conversion.
+// Unlike the former conversion, malformed numbers are reported instead of being turned into 0.
+func (c Config) Resolve() (ConfigV2, error) {
+	var errs []error
+	parse := func(field string, value string) int {
+		if value == "" {
+			return 0 // use the default
+		}
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, &FieldError{Field: field, Err: fmt.Errorf("invalid integer %q", value)})
+		}
+		return i
+	}
+
+	typed := ConfigV2{
+		LogFile:     c.LogFile,
+		LogRotation: c.LogRotation,
+		MaxSizeMB:   parse("MaxSizeMB", c.MaxSizeMB),
+		MaxBackups:  parse("MaxBackups", c.MaxBackups),
+		MaxAge:      time.Duration(parse("MaxAge", c.MaxAge)) * 24 * time.Hour,
+		SlogHandler: c.SlogHandler,
+	}
+	typed.UnlimitedBackups = c.MaxBackups != "" && typed.MaxBackups == 0
+	typed.UnlimitedAge = c.MaxAge != "" && typed.MaxAge == 0
+	if err := errors.Join(errs...); err != nil {
+		return ConfigV2{}, err
+	}
+	return typed.Resolve()
+}
@@ -0,0 +1,356 @@
+package common_logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*
+	The configuration is assembled from the following sources, each one overriding the previous ones:
+
+	1. the defaults of zapLogger/definitions.go (see DefaultConfigV2)
+	2. a JSON or YAML file (see ConfigFromFile)
+	3. the environment variables (see ConfigFromEnv)
+	4. the explicit options passed to LoadConfig (e.g. WithLevel)
+*/
+
+// LoadedConfig is a ConfigV2 assembled from several sources, which remembers where each value came from
+type LoadedConfig struct {
+	ConfigV2
+	sources map[string]string // field name -> source of its value, e.g. "env LOG_LEVEL"
+}
+
+// ConfigOption explicitly sets a configuration value, overriding the file and the environment
+type ConfigOption struct {
+	field string // the field of ConfigV2 it sets, whose source becomes "option" even if its value doesn't change
+	set   func(*ConfigV2)
+}
+
+// The environment variables, without their prefix, e.g. LOG_FILE for the prefix "LOG"
+const (
+	EnvLogFile     = "FILE"
+	EnvLogRotation = "ROTATION"
+	EnvMaxSizeMB   = "MAX_SIZE"
+	EnvMaxBackups  = "MAX_BACKUPS"
+	EnvMaxAge      = "MAX_AGE" // days (e.g. 7) or duration (e.g. 168h)
+	EnvLevel       = "LEVEL"   // debug, info, warn or error
+	EnvOutputs     = "OUTPUTS" // comma-separated, e.g. stdout,file
+)
+
+const (
+	sourceDefault = "default" // source of the values that are not set anywhere
+	sourceOption  = "option"  // source of the values set by a ConfigOption
+)
+
+// loadableFields are the fields of ConfigV2 that can be loaded from a file or the environment, in display order
+var loadableFields = []string{"LogFile", "LogRotation", "MaxSizeMB", "MaxBackups", "MaxAge", "Level", "Outputs"}
+
+// fileConfig is the format of the configuration files. Pointers tell the unset fields apart.
+type fileConfig struct {
+	LogFile     *string   `json:"logFile" yaml:"logFile"`
+	LogRotation *bool     `json:"logRotation" yaml:"logRotation"`
+	MaxSizeMB   *int      `json:"maxSizeMB" yaml:"maxSizeMB"`
+	MaxBackups  *int      `json:"maxBackups" yaml:"maxBackups"`
+	MaxAge      *fileAge  `json:"maxAge" yaml:"maxAge"`
+	Level       *Level    `json:"level" yaml:"level"`
+	Outputs     *[]string `json:"outputs" yaml:"outputs"`
+}
+
+// fileAge accepts a number of days (7) or a duration ("168h")
+type fileAge time.Duration
+
+func (a *fileAge) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		s = string(b) // a number of days
+	}
+	return a.parse(s)
+}
+
+func (a *fileAge) UnmarshalYAML(node *yaml.Node) error {
+	return a.parse(node.Value)
+}
+
+func (a *fileAge) parse(s string) error {
+	d, err := parseAge(s)
+	if err != nil {
+		return err
+	}
+	*a = fileAge(d)
+	return nil
+}
+
+// newLoadedConfig returns the default configuration
+func newLoadedConfig() LoadedConfig {
+	c := LoadedConfig{
+		ConfigV2: DefaultConfigV2(),
+		sources:  make(map[string]string, len(loadableFields)),
+	}
+	for _, field := range loadableFields {
+		c.sources[field] = sourceDefault
+	}
+	return c
+}
+
+// ConfigFromEnv returns the default configuration overridden by the environment variables with the given prefix,
+// e.g. LOG_FILE, LOG_ROTATION, LOG_MAX_SIZE, LOG_MAX_BACKUPS, LOG_MAX_AGE, LOG_LEVEL and LOG_OUTPUTS for the prefix "LOG"
+func ConfigFromEnv(prefix string) (LoadedConfig, error) {
+	c := newLoadedConfig()
+	err := c.applyEnv(prefix)
+	return c, err
+}
+
+// ConfigFromFile returns the default configuration overridden by the given JSON (.json) or YAML (.yaml, .yml) file.
+// Unknown keys are reported as errors.
+func ConfigFromFile(path string) (LoadedConfig, error) {
+	c := newLoadedConfig()
+	err := c.applyFile(path)
+	return c, err
+}
+
+// LoadConfig assembles the configuration from the defaults, the file (if path is not empty),
+// the environment variables with the given prefix (if prefix is not empty) and the options, in this order of precedence
+func LoadConfig(path string, envPrefix string, options ...ConfigOption) (LoadedConfig, error) {
+	c := newLoadedConfig()
+
+	if path != "" {
+		if err := c.applyFile(path); err != nil {
+			return c, err
+		}
+	}
+
+	if envPrefix != "" {
+		if err := c.applyEnv(envPrefix); err != nil {
+			return c, err
+		}
+	}
+
+	for _, option := range options {
+		option.set(&c.ConfigV2)
+		c.sources[option.field] = sourceOption
+	}
+
+	return c, nil
+}
+
+// Source returns where the value of the given field (e.g. "Level") came from, e.g. "env LOG_LEVEL"
+func (c LoadedConfig) Source(field string) string {
+	return c.sources[field]
+}
+
+// Explain returns the effective value of every field along with its source, one field per line
+func (c LoadedConfig) Explain() string {
+	var b strings.Builder
+	for _, field := range loadableFields {
+		value := fieldOf(&c.ConfigV2, field).Interface()
+		if outputs, ok := value.([]string); ok {
+			value = strings.Join(outputs, ",")
+		}
+		fmt.Fprintf(&b, "%-11s = %v (%s)\n", field, value, c.sources[field])
+	}
+	return b.String()
+}
+
+// mergeInto returns base with the fields that can be loaded replaced by the loaded ones
+func (c LoadedConfig) mergeInto(base ConfigV2) ConfigV2 {
+	for _, field := range loadableFields {
+		fieldOf(&base, field).Set(fieldOf(&c.ConfigV2, field))
+	}
+	// the loaded limits of the rotated files replace the unlimited ones
+	base.UnlimitedBackups = base.UnlimitedBackups && base.MaxBackups == 0
+	base.UnlimitedAge = base.UnlimitedAge && base.MaxAge == 0
+	return base
+}
+
+// set copies the given fields of values, recording their sources
+func (c *LoadedConfig) set(values ConfigV2, sources map[string]string) {
+	for field, source := range sources {
+		fieldOf(&c.ConfigV2, field).Set(fieldOf(&values, field))
+		c.sources[field] = source
+	}
+}
+
+func (c *LoadedConfig) applyEnv(prefix string) error {
+	var (
+		values  ConfigV2
+		sources = make(map[string]string)
+		errs    []error
+	)
+
+	lookup := func(field string, suffix string, parse func(string) error) {
+		name := prefix + "_" + suffix
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+		if err := parse(strings.TrimSpace(value)); err != nil {
+			errs = append(errs, &FieldError{Field: field, Err: fmt.Errorf("invalid %s: %w", name, err)})
+			return
+		}
+		sources[field] = "env " + name
+	}
+
+	lookup("LogFile", EnvLogFile, func(s string) error {
+		values.LogFile = s
+		return nil
+	})
+	lookup("LogRotation", EnvLogRotation, func(s string) (err error) {
+		values.LogRotation, err = strconv.ParseBool(s)
+		return
+	})
+	lookup("MaxSizeMB", EnvMaxSizeMB, func(s string) (err error) {
+		values.MaxSizeMB, err = strconv.Atoi(s)
+		return
+	})
+	lookup("MaxBackups", EnvMaxBackups, func(s string) (err error) {
+		values.MaxBackups, err = strconv.Atoi(s)
+		return
+	})
+	lookup("MaxAge", EnvMaxAge, func(s string) (err error) {
+		values.MaxAge, err = parseAge(s)
+		return
+	})
+	lookup("Level", EnvLevel, func(s string) (err error) {
+		values.Level, err = ParseLevel(s)
+		return
+	})
+	lookup("Outputs", EnvOutputs, func(s string) error {
+		values.Outputs = splitList(s)
+		return nil
+	})
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	c.set(values, sources)
+	return nil
+}
+
+func (c *LoadedConfig) applyFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file fileConfig
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&file)
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&file)
+	default:
+		return fmt.Errorf("unsupported config file extension %q, expected .json, .yaml or .yml", ext)
+	}
+	if err != nil && !errors.Is(err, io.EOF) { // an empty file sets nothing
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	var (
+		values  ConfigV2
+		sources = make(map[string]string)
+		source  = "file " + path
+	)
+
+	if file.LogFile != nil {
+		values.LogFile, sources["LogFile"] = *file.LogFile, source
+	}
+	if file.LogRotation != nil {
+		values.LogRotation, sources["LogRotation"] = *file.LogRotation, source
+	}
+	if file.MaxSizeMB != nil {
+		values.MaxSizeMB, sources["MaxSizeMB"] = *file.MaxSizeMB, source
+	}
+	if file.MaxBackups != nil {
+		values.MaxBackups, sources["MaxBackups"] = *file.MaxBackups, source
+	}
+	if file.MaxAge != nil {
+		values.MaxAge, sources["MaxAge"] = time.Duration(*file.MaxAge), source
+	}
+	if file.Level != nil {
+		values.Level, sources["Level"] = *file.Level, source
+	}
+	if file.Outputs != nil {
+		values.Outputs, sources["Outputs"] = *file.Outputs, source
+	}
+
+	c.set(values, sources)
+	return nil
+}
+
+// WithLogFile sets the path of the log file
+func WithLogFile(path string) ConfigOption {
+	return ConfigOption{field: "LogFile", set: func(c *ConfigV2) { c.LogFile = path }}
+}
+
+// WithLogRotation enables or disables the rotation of the log file
+func WithLogRotation(enabled bool) ConfigOption {
+	return ConfigOption{field: "LogRotation", set: func(c *ConfigV2) { c.LogRotation = enabled }}
+}
+
+// WithMaxSizeMB sets the maximum size of a log file before it gets rotated
+func WithMaxSizeMB(size int) ConfigOption {
+	return ConfigOption{field: "MaxSizeMB", set: func(c *ConfigV2) { c.MaxSizeMB = size }}
+}
+
+// WithMaxBackups sets the number of rotated files to keep
+func WithMaxBackups(backups int) ConfigOption {
+	return ConfigOption{field: "MaxBackups", set: func(c *ConfigV2) { c.MaxBackups = backups }}
+}
+
+// WithMaxAge sets the retention of the rotated files
+func WithMaxAge(age time.Duration) ConfigOption {
+	return ConfigOption{field: "MaxAge", set: func(c *ConfigV2) { c.MaxAge = age }}
+}
+
+// WithLevel sets the initial global level
+func WithLevel(level Level) ConfigOption {
+	return ConfigOption{field: "Level", set: func(c *ConfigV2) { c.Level = level }}
+}
+
+// WithOutputs sets the outputs to write to
+func WithOutputs(outputs ...string) ConfigOption {
+	return ConfigOption{field: "Outputs", set: func(c *ConfigV2) { c.Outputs = outputs }}
+}
+
+// parseAge parses a number of days (e.g. "7") or a duration (e.g. "168h")
+func parseAge(s string) (time.Duration, error) {
+	if days, err := strconv.Atoi(s); err == nil {
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q, expected days (e.g. 7) or a duration (e.g. 168h)", s)
+	}
+	return d, nil
+}
+
+// splitList splits a comma-separated list, ignoring the blanks
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// fieldOf returns the named field of the configuration
+func fieldOf(c *ConfigV2, field string) reflect.Value {
+	return reflect.ValueOf(c).Elem().FieldByName(field)
+}
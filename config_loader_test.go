@@ -0,0 +1,116 @@
+package common_logger
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *LoggerTestSuite) TestLoadConfigPrecedence() {
+
+	var err error
+
+	// arrange
+	suite.tempDir, err = os.MkdirTemp("", "my-temp-dir-*")
+	path := filepath.Join(suite.tempDir, "logger.yaml")
+	content := "logFile: /var/log/app.log\nmaxSizeMB: 50\nmaxAge: 2\nlevel: warn\noutputs: [file]\n"
+	err = os.WriteFile(path, []byte(content), 0o644)
+
+	suite.T().Setenv("LOG_MAX_SIZE", "20")
+	suite.T().Setenv("LOG_LEVEL", "debug")
+
+	// act
+	config, loadErr := LoadConfig(path, "LOG", WithLevel(ErrorLevel))
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), loadErr)
+	assert.Equal(suite.T(), "/var/log/app.log", config.LogFile)
+	assert.Equal(suite.T(), 20, config.MaxSizeMB)
+	assert.Equal(suite.T(), 48*time.Hour, config.MaxAge)
+	assert.Equal(suite.T(), ErrorLevel, config.Level)
+	assert.Equal(suite.T(), []string{OutputFile}, config.Outputs)
+	assert.Equal(suite.T(), 3, config.MaxBackups)
+
+	assert.Equal(suite.T(), "file "+path, config.Source("LogFile"))
+	assert.Equal(suite.T(), "env LOG_MAX_SIZE", config.Source("MaxSizeMB"))
+	assert.Equal(suite.T(), "option", config.Source("Level"))
+	assert.Equal(suite.T(), "default", config.Source("MaxBackups"))
+
+	explained := config.Explain()
+	assert.Contains(suite.T(), explained, "MaxSizeMB   = 20 (env LOG_MAX_SIZE)\n")
+	assert.Contains(suite.T(), explained, "Outputs     = file (file "+path+")\n")
+	assert.Contains(suite.T(), explained, "Level       = error (option)\n")
+}
+
+func (suite *LoggerTestSuite) TestLoadConfigOptionSettingTheSameValue() {
+
+	// arrange
+	suite.T().Setenv("LOG_LEVEL", "info")
+
+	// act
+	config, err := LoadConfig("", "LOG", WithLevel(InfoLevel), WithMaxBackups(DefaultConfigV2().MaxBackups))
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), InfoLevel, config.Level)
+	assert.Equal(suite.T(), "option", config.Source("Level"))
+	assert.Equal(suite.T(), "option", config.Source("MaxBackups"))
+	assert.Equal(suite.T(), "default", config.Source("MaxAge"))
+}
+
+func (suite *LoggerTestSuite) TestConfigFromJSONFile() {
+
+	var err error
+
+	// arrange
+	suite.tempDir, err = os.MkdirTemp("", "my-temp-dir-*")
+	path := filepath.Join(suite.tempDir, "logger.json")
+	err = os.WriteFile(path, []byte(`{"maxAge": "36h", "logRotation": true, "maxBackups": 1}`), 0o644)
+
+	// act
+	config, loadErr := ConfigFromFile(path)
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), loadErr)
+	assert.Equal(suite.T(), 36*time.Hour, config.MaxAge)
+	assert.True(suite.T(), config.LogRotation)
+	assert.Equal(suite.T(), 1, config.MaxBackups)
+	assert.Equal(suite.T(), DefaultConfigV2().LogFile, config.LogFile)
+}
+
+func (suite *LoggerTestSuite) TestConfigFromFileRejectsUnknownKeys() {
+
+	var err error
+
+	// arrange
+	suite.tempDir, err = os.MkdirTemp("", "my-temp-dir-*")
+	path := filepath.Join(suite.tempDir, "logger.yml")
+	err = os.WriteFile(path, []byte("maxSize: 10\n"), 0o644)
+
+	// act
+	_, loadErr := ConfigFromFile(path)
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.ErrorContains(suite.T(), loadErr, "field maxSize not found")
+}
+
+func (suite *LoggerTestSuite) TestConfigFromEnvReportsInvalidValues() {
+
+	// arrange
+	suite.T().Setenv("APP_LOG_MAX_BACKUPS", "three")
+	suite.T().Setenv("APP_LOG_LEVEL", "verbose")
+	suite.T().Setenv("APP_LOG_OUTPUTS", "stdout, stderr")
+
+	// act
+	config, err := ConfigFromEnv("APP_LOG")
+
+	// assert
+	assert.ErrorContains(suite.T(), err, `MaxBackups: invalid APP_LOG_MAX_BACKUPS: strconv.Atoi: parsing "three": invalid syntax`)
+	assert.ErrorContains(suite.T(), err, `Level: invalid APP_LOG_LEVEL: unknown level "verbose"`)
+	assert.Equal(suite.T(), DefaultConfigV2().Outputs, config.Outputs)
+}
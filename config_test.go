@@ -0,0 +1,106 @@
+package common_logger
+
+import (
+	"errors"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vlbarou/logger/default_logger"
+	"github.com/vlbarou/logger/zapLogger"
+	"go.uber.org/zap/zapcore"
+)
+
+func (suite *LoggerTestSuite) TestValidateReportsAllInvalidFields() {
+
+	// arrange
+	config := ConfigV2{
+		MaxSizeMB:   -1,
+		MaxBackups:  -2,
+		MaxAge:      -time.Hour,
+		Level:       Level(42),
+		Outputs:     []string{OutputFile, "syslog", OutputFile},
+		Async:       AsyncConfig{QueueSize: -1, Policy: OverflowPolicy(9), FlushInterval: -time.Second},
+		DedupWindow: -time.Minute,
+		Redaction:   RedactionConfig{Mode: RedactMode(5)},
+		AdminServer: true,
+		AdminPort:   "http",
+		Admin:       AdminConfig{Host: "0.0.0.0", KeyFile: "server.key"},
+		Sampling: SamplingConfig{
+			Default: SamplingRule{First: -1, Interval: time.Second},
+			Levels:  map[Level]SamplingRule{WarnLevel: {Sampler: Sampler(7)}},
+			Loggers: map[string]SamplingRule{"db": {Thereafter: -3}},
+		},
+	}
+
+	// act
+	err := config.Validate()
+
+	// assert
+	var fieldErr *FieldError
+	assert.True(suite.T(), errors.As(err, &fieldErr))
+	assert.ErrorContains(suite.T(), err, "MaxSizeMB: must not be negative, got -1")
+	assert.ErrorContains(suite.T(), err, "MaxBackups: must not be negative, got -2")
+	assert.ErrorContains(suite.T(), err, "MaxAge: must not be negative, got -1h0m0s")
+	assert.ErrorContains(suite.T(), err, "Level: unknown level Level(42)")
+	assert.ErrorContains(suite.T(), err, `Outputs: unknown output "syslog"`)
+	assert.ErrorContains(suite.T(), err, `Outputs: duplicate output "file"`)
+	assert.ErrorContains(suite.T(), err, `LogFile: is required by the "file" output`)
+	assert.ErrorContains(suite.T(), err, "Async.QueueSize: must not be negative, got -1")
+	assert.ErrorContains(suite.T(), err, "Async.Policy: unknown policy OverflowPolicy(9)")
+	assert.ErrorContains(suite.T(), err, "Async.FlushInterval: must not be negative, got -1s")
+	assert.ErrorContains(suite.T(), err, "Sampling.Default.First: must not be negative, got -1")
+	assert.ErrorContains(suite.T(), err, "Sampling.Levels[warn].Sampler: unknown sampler Sampler(7)")
+	assert.ErrorContains(suite.T(), err, `Sampling.Loggers["db"].Thereafter: must not be negative, got -3`)
+	assert.ErrorContains(suite.T(), err, "DedupWindow: must not be negative, got -1m0s")
+	assert.ErrorContains(suite.T(), err, "Redaction: unknown mode RedactMode(5)")
+	assert.ErrorContains(suite.T(), err, `AdminPort: invalid port "http"`)
+	assert.ErrorContains(suite.T(), err, "Admin: CertFile and KeyFile must be set together")
+	assert.ErrorContains(suite.T(), err, "the log server would accept unauthenticated requests on 0.0.0.0")
+}
+
+func (suite *LoggerTestSuite) TestGetLoggerWithMalformedConfig() {
+
+	// act
+	l, err := GetLogger(Zap, Config{MaxSizeMB: "1O", MaxAge: "seven"})
+
+	// assert
+	_, ok := l.(*default_logger.DefaultLogger)
+	assert.True(suite.T(), ok)
+	assert.ErrorContains(suite.T(), err, `MaxSizeMB: invalid integer "1O"`)
+	assert.ErrorContains(suite.T(), err, `MaxAge: invalid integer "seven"`)
+}
+
+func (suite *LoggerTestSuite) TestGetLoggerWithConfigV2() {
+
+	var err error
+
+	// arrange
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	// act
+	_, err = GetLogger(Zap, ConfigV2{
+		LogFile:    suite.tempLogFile.Name(),
+		MaxBackups: 5,
+		MaxAge:     36 * time.Hour,
+		Level:      DebugLevel,
+		Outputs:    []string{OutputFile},
+	})
+
+	// assert
+	l, ok := instance().(*zapLogger.LoggerImpl)
+
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), zapcore.DebugLevel, l.Level(""))
+
+	maxAge, _ := getLoggerFieldValue(l, MaxAge).(int)
+	assert.Equal(suite.T(), 2, maxAge)
+
+	maxBackups, _ := getLoggerFieldValue(l, MaxBackups).(int)
+	assert.Equal(suite.T(), 5, maxBackups)
+
+	maxSizeMB, _ := getLoggerFieldValue(l, MaxSizeMB).(int)
+	assert.Equal(suite.T(), 10, maxSizeMB)
+
+	assert.Nil(suite.T(), Shutdown())
+}
@@ -0,0 +1,147 @@
+package common_logger
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vlbarou/logger/logapi"
+)
+
+// ContextExtractor returns the key-value pairs that should be logged for the given context
+// (e.g. the request ID or the trace ID stored in it by a middleware)
+type ContextExtractor func(ctx context.Context) []any
+
+type (
+	loggerKey struct{}
+	fieldsKey struct{}
+)
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   = []ContextExtractor{contextFields}
+)
+
+// contextLogger is the logger carried by a context, along with its child used by the *Ctx functions
+type contextLogger struct {
+	logger  Logger
+	skipped func() Logger // the logger skipping the frame of the *Ctx function, created on first use
+}
+
+// WithContext returns a copy of ctx that carries the given logger
+func WithContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, &contextLogger{
+		logger:  logger,
+		skipped: sync.OnceValue(func() Logger { return logapi.WithCallerSkip(logger, 1) }),
+	})
+}
+
+// FromContext returns the logger carried by ctx, or the global logger if there is none
+func FromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey{}).(*contextLogger); ok {
+			return l.logger
+		}
+	}
+	return instance()
+}
+
+// WithFields returns a copy of ctx that carries the given key-value pairs,
+// in addition to the ones already attached to ctx. They are logged by the *Ctx functions.
+func WithFields(ctx context.Context, args ...any) context.Context {
+	existing, _ := ctx.Value(fieldsKey{}).([]any)
+	return context.WithValue(ctx, fieldsKey{}, append(existing[:len(existing):len(existing)], args...))
+}
+
+// RegisterContextExtractor adds an extractor whose fields are logged by the *Ctx functions
+func RegisterContextExtractor(extractor ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors = append(extractors, extractor)
+}
+
+// ContextValue returns an extractor that logs the value stored in the context under key as the given field,
+// e.g. RegisterContextExtractor(ContextValue(requestIDKey{}, "request_id"))
+func ContextValue(key any, field string) ContextExtractor {
+	return func(ctx context.Context) []any {
+		if v := ctx.Value(key); v != nil {
+			return []any{field, v}
+		}
+		return nil
+	}
+}
+
+// contextFields is the extractor of the fields attached with WithFields
+func contextFields(ctx context.Context) []any {
+	fields, _ := ctx.Value(fieldsKey{}).([]any)
+	return fields
+}
+
+// extractFields prepends the fields extracted from ctx to args
+func extractFields(ctx context.Context, args []any) []any {
+	if ctx == nil {
+		return args
+	}
+
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+
+	var fields []any
+	for _, extractor := range extractors {
+		fields = append(fields, extractor(ctx)...)
+	}
+	if len(fields) == 0 {
+		return args
+	}
+	return append(fields, args...)
+}
+
+// ctxLogger returns the logger carried by ctx, or the global logger, skipping the frame of the *Ctx function to report the caller.
+// The child that skips the frame is created once per context, like the one of the global logger.
+func ctxLogger(ctx context.Context) Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey{}).(*contextLogger); ok {
+			return l.skipped()
+		}
+	}
+	return global()
+}
+
+// TraceCtx logs a trace-level message with the logger and the fields carried by ctx
+func TraceCtx(ctx context.Context, msg string, fields ...any) {
+	ctxLogger(ctx).Trace(msg, extractFields(ctx, fields)...)
+}
+
+// InfoCtx logs an info-level message with the logger and the fields carried by ctx
+func InfoCtx(ctx context.Context, msg string, fields ...any) {
+	ctxLogger(ctx).Info(msg, extractFields(ctx, fields)...)
+}
+
+// DebugCtx logs a debug-level message with the logger and the fields carried by ctx
+func DebugCtx(ctx context.Context, msg string, fields ...any) {
+	ctxLogger(ctx).Debug(msg, extractFields(ctx, fields)...)
+}
+
+// WarnCtx logs a warn-level message with the logger and the fields carried by ctx
+func WarnCtx(ctx context.Context, msg string, fields ...any) {
+	ctxLogger(ctx).Warn(msg, extractFields(ctx, fields)...)
+}
+
+// ErrorCtx logs an error-level message with the logger and the fields carried by ctx
+func ErrorCtx(ctx context.Context, msg string, fields ...any) {
+	ctxLogger(ctx).Error(msg, extractFields(ctx, fields)...)
+}
+
+// DPanicCtx logs a dpanic-level message with the logger and the fields carried by ctx, and panics in development mode
+func DPanicCtx(ctx context.Context, msg string, fields ...any) {
+	ctxLogger(ctx).DPanic(msg, extractFields(ctx, fields)...)
+}
+
+// PanicCtx logs a panic-level message with the logger and the fields carried by ctx, then panics
+func PanicCtx(ctx context.Context, msg string, fields ...any) {
+	ctxLogger(ctx).Panic(msg, extractFields(ctx, fields)...)
+}
+
+// FatalCtx logs a fatal-level message with the logger and the fields carried by ctx, flushes the outputs, then exits
+func FatalCtx(ctx context.Context, msg string, fields ...any) {
+	ctxLogger(ctx).Fatal(msg, extractFields(ctx, fields)...)
+}
@@ -0,0 +1,76 @@
+package common_logger
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vlbarou/logger/mocks"
+)
+
+type requestIDKey struct{}
+
+func (suite *LoggerTestSuite) TestFromContextFallsBackToGlobalLogger() {
+
+	// arrange
+	setInstance(new(mocks.Logger))
+
+	// act
+	l := FromContext(context.Background())
+
+	// assert
+	assert.Same(suite.T(), instance(), l)
+}
+
+func (suite *LoggerTestSuite) TestLogWithContext() {
+
+	// arrange
+	setInstance(new(mocks.Logger))
+	global, _ := instance().(*mocks.Logger)
+	l := new(mocks.Logger)
+
+	defer func(saved []ContextExtractor) { extractors = saved }(extractors)
+	RegisterContextExtractor(ContextValue(requestIDKey{}, "request_id"))
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "42")
+	ctx = WithFields(ctx, "user_id", "u1")
+	ctx = WithContext(ctx, l)
+
+	l.On("Info", "test", "user_id", "u1", "request_id", "42", "key", "value")
+	l.On("Error", "test", "user_id", "u1", "request_id", "42")
+
+	// act
+	InfoCtx(ctx, "test", "key", "value")
+	ErrorCtx(ctx, "test")
+
+	// assert
+	l.AssertExpectations(suite.T())
+	global.AssertExpectations(suite.T())
+}
+
+// skipCounter counts the children created with WithCallerSkip
+type skipCounter struct {
+	*mocks.Logger
+	skips int
+}
+
+func (s *skipCounter) WithCallerSkip(int) Logger {
+	s.skips++
+	return s.Logger
+}
+
+func (suite *LoggerTestSuite) TestContextLoggerSkipsOnce() {
+
+	// arrange
+	l := &skipCounter{Logger: new(mocks.Logger)}
+	ctx := WithContext(context.Background(), l)
+
+	// act
+	first := ctxLogger(ctx)
+	second := ctxLogger(ctx)
+
+	// assert
+	assert.Equal(suite.T(), 1, l.skips)
+	assert.Same(suite.T(), l.Logger, first)
+	assert.Same(suite.T(), first, second)
+	assert.Same(suite.T(), l, FromContext(ctx))
+}
@@ -0,0 +1,79 @@
+package common_logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vlbarou/logger/default_logger"
+)
+
+func (suite *LoggerTestSuite) TestDefaultLoggerDedup() {
+
+	// arrange
+	var buf bytes.Buffer
+	exitCode := -1
+	l := default_logger.NewWithOutput(&buf).WithDedup(time.Minute).WithExitFunc(func(code int) { exitCode = code })
+	db := l.Named("db")
+
+	// act
+	for i := 0; i < 3; i++ {
+		db.Warn("retrying", "attempt", 1)
+	}
+	db.Warn("retrying", "attempt", 2)
+	l.Error("failed")
+	l.Error("failed")
+	l.Sync()
+	l.Fatal("stop")
+
+	// assert
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	assert.Len(suite.T(), lines, 6)
+	assert.Contains(suite.T(), lines[0], `msg="retrying" logger=db attempt=1`)
+	assert.Contains(suite.T(), lines[1], `level=WARN`)
+	assert.Contains(suite.T(), lines[1], `msg="last message repeated 2 times" logger=db repeated=2 repeat_span=`)
+	assert.Contains(suite.T(), lines[2], `msg="retrying" logger=db attempt=2`)
+	assert.Contains(suite.T(), lines[3], `msg="failed"`)
+	assert.Contains(suite.T(), lines[4], `msg="last message repeated 1 times" repeated=1`)
+	assert.Contains(suite.T(), lines[5], `level=FATAL`)
+	assert.Equal(suite.T(), 1, exitCode)
+}
+
+// lockedBuffer is a buffer written by the timers of the logger while the test reads it
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (suite *LoggerTestSuite) TestDefaultLoggerDedupWindowExpiry() {
+
+	// arrange
+	var buf lockedBuffer
+	l := default_logger.NewWithOutput(&buf).WithDedup(50 * time.Millisecond)
+
+	// act
+	for i := 0; i < 3; i++ {
+		l.Info("burst")
+	}
+
+	// assert: the summary is printed once the window expires, without any other record nor sync
+	assert.Eventually(suite.T(), func() bool {
+		return strings.Contains(buf.String(), `msg="last message repeated 2 times" repeated=2`)
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(suite.T(), 2, strings.Count(buf.String(), "\n"))
+}
@@ -2,63 +2,382 @@ package default_logger
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/vlbarou/logger/logapi"
 )
 
 type DefaultLogger struct {
-	logger *log.Logger
-	file   *os.File
+	logger    *log.Logger
+	file      *os.File
+	fields    []any          // key-value pairs bound with `With`, prepended to every record
+	name      string         // name of the logger, as set by `Named`, e.g. "db.pool"
+	exit      func(int)      // called by Fatal (os.Exit by default)
+	malformed *atomic.Uint64 // malformed key-value arguments, shared with the children
+	dedup     *dedup         // collapses the consecutive duplicates, shared with the children; nil if disabled
+	redactor  *logapi.Redactor
+}
+
+// dedup is the last record printed by a logger and its children, with its repetitions
+type dedup struct {
+	window   time.Duration
+	mu       sync.Mutex
+	last     string // level, message and fields of the last record
+	level    string
+	name     string
+	first    time.Time   // time of the last record
+	lastSeen time.Time   // time of its last repetition
+	repeated int         // repetitions of the last record, not printed yet
+	timer    *time.Timer // prints the summary once the window of the last record expires, set on its first repetition
 }
 
 func New() *DefaultLogger {
+	return NewWithOutput(os.Stdout)
+}
+
+// NewWithOutput returns a logger that writes to w
+func NewWithOutput(w io.Writer) *DefaultLogger {
 	return &DefaultLogger{
-		logger: log.New(os.Stdout, "", log.LstdFlags),
+		logger:    log.New(w, "", log.LstdFlags),
+		exit:      os.Exit,
+		malformed: new(atomic.Uint64),
 	}
 }
 
+// WithExitFunc sets the function called by Fatal (os.Exit by default)
+func (d *DefaultLogger) WithExitFunc(exit func(int)) *DefaultLogger {
+	d.exit = exit
+	return d
+}
+
+// WithDedup collapses the consecutive records of the same level, message and fields printed within the window:
+// only the first one is printed, followed by a summary of its repetitions (`last message repeated N times`)
+// when the window expires, a different record is printed, or the logger is synced or shut down.
+// A zero window disables the collapsing.
+func (d *DefaultLogger) WithDedup(window time.Duration) *DefaultLogger {
+	d.dedup = nil
+	if window > 0 {
+		d.dedup = &dedup{window: window}
+	}
+	return d
+}
+
+// WithRedactor redacts the sensitive values of the messages and of the fields, see logapi.Redactor.
+// A nil redactor disables the redaction.
+func (d *DefaultLogger) WithRedactor(r *logapi.Redactor) *DefaultLogger {
+	d.redactor = r
+	return d
+}
+
+// Trace logs a trace-level message with structured key-value pairs.
+func (d *DefaultLogger) Trace(msg string, args ...any) {
+	d.log("TRACE", msg, args)
+}
+
 // Debug logs an info-level message with structured key-value pairs.
 func (d *DefaultLogger) Debug(msg string, args ...any) {
-	d.logger.Println(createLog(msg, "DEBUG", args...))
+	d.log("DEBUG", msg, args)
 }
 
 // Info logs an info-level message with structured key-value pairs.
 func (d *DefaultLogger) Info(msg string, args ...any) {
-	d.logger.Println(createLog(msg, "INFO", args...))
+	d.log("INFO", msg, args)
 }
 
 // Warn logs an info-level message with structured key-value pairs.
 func (d *DefaultLogger) Warn(msg string, args ...any) {
-	d.logger.Println(createLog(msg, "WARN", args...))
+	d.log("WARN", msg, args)
 }
 
 // Error logs an info-level message with structured key-value pairs.
 func (d *DefaultLogger) Error(msg string, args ...any) {
-	d.logger.Println(createLog(msg, "ERROR", args...))
+	d.log("ERROR", msg, args)
+}
+
+// Tracef logs a trace-level message formatted with fmt.Sprintf.
+func (d *DefaultLogger) Tracef(format string, args ...any) {
+	d.print("TRACE", fmt.Sprintf(format, args...), d.withFields(nil))
+}
+
+// Debugf logs a debug-level message formatted with fmt.Sprintf.
+func (d *DefaultLogger) Debugf(format string, args ...any) {
+	d.print("DEBUG", fmt.Sprintf(format, args...), d.withFields(nil))
+}
+
+// Infof logs a info-level message formatted with fmt.Sprintf.
+func (d *DefaultLogger) Infof(format string, args ...any) {
+	d.print("INFO", fmt.Sprintf(format, args...), d.withFields(nil))
+}
+
+// Warnf logs a warn-level message formatted with fmt.Sprintf.
+func (d *DefaultLogger) Warnf(format string, args ...any) {
+	d.print("WARN", fmt.Sprintf(format, args...), d.withFields(nil))
+}
+
+// Errorf logs a error-level message formatted with fmt.Sprintf.
+func (d *DefaultLogger) Errorf(format string, args ...any) {
+	d.print("ERROR", fmt.Sprintf(format, args...), d.withFields(nil))
+}
+
+// TraceFn logs a trace-level message built by fn.
+func (d *DefaultLogger) TraceFn(fn logapi.MessageFunc) {
+	msg, args := fn()
+	d.log("TRACE", msg, args)
+}
+
+// DebugFn logs a debug-level message built by fn.
+func (d *DefaultLogger) DebugFn(fn logapi.MessageFunc) {
+	msg, args := fn()
+	d.log("DEBUG", msg, args)
+}
+
+// InfoFn logs a info-level message built by fn.
+func (d *DefaultLogger) InfoFn(fn logapi.MessageFunc) {
+	msg, args := fn()
+	d.log("INFO", msg, args)
+}
+
+// WarnFn logs a warn-level message built by fn.
+func (d *DefaultLogger) WarnFn(fn logapi.MessageFunc) {
+	msg, args := fn()
+	d.log("WARN", msg, args)
+}
+
+// ErrorFn logs a error-level message built by fn.
+func (d *DefaultLogger) ErrorFn(fn logapi.MessageFunc) {
+	msg, args := fn()
+	d.log("ERROR", msg, args)
+}
+
+// TraceF logs a trace-level message with typed fields.
+func (d *DefaultLogger) TraceF(msg string, fields ...logapi.Field) {
+	d.print("TRACE", msg, d.withFields(logapi.FieldArgs(fields)))
+}
+
+// DebugF logs a debug-level message with typed fields.
+func (d *DefaultLogger) DebugF(msg string, fields ...logapi.Field) {
+	d.print("DEBUG", msg, d.withFields(logapi.FieldArgs(fields)))
+}
+
+// InfoF logs a info-level message with typed fields.
+func (d *DefaultLogger) InfoF(msg string, fields ...logapi.Field) {
+	d.print("INFO", msg, d.withFields(logapi.FieldArgs(fields)))
+}
+
+// WarnF logs a warn-level message with typed fields.
+func (d *DefaultLogger) WarnF(msg string, fields ...logapi.Field) {
+	d.print("WARN", msg, d.withFields(logapi.FieldArgs(fields)))
+}
+
+// ErrorF logs a error-level message with typed fields.
+func (d *DefaultLogger) ErrorF(msg string, fields ...logapi.Field) {
+	d.print("ERROR", msg, d.withFields(logapi.FieldArgs(fields)))
+}
+
+// Enabled returns true, since the default logger logs every level.
+func (d *DefaultLogger) Enabled(logapi.Level) bool {
+	return true
+}
+
+// DPanic logs a dpanic-level message with structured key-value pairs. The default logger has no development mode, so it doesn't panic.
+func (d *DefaultLogger) DPanic(msg string, args ...any) {
+	d.log("DPANIC", msg, args)
+}
+
+// Panic logs a panic-level message with structured key-value pairs, then panics.
+func (d *DefaultLogger) Panic(msg string, args ...any) {
+	d.log("PANIC", msg, args)
+	panic(msg)
+}
+
+// Fatal logs a fatal-level message with structured key-value pairs, then calls the exit function.
+func (d *DefaultLogger) Fatal(msg string, args ...any) {
+	d.log("FATAL", msg, args)
+	d.exit(1)
+}
+
+// With returns a child logger that adds the given key-value pairs to every record.
+func (d *DefaultLogger) With(args ...any) logapi.Logger {
+	child := *d
+	child.fields = append(d.fields[:len(d.fields):len(d.fields)], d.normalize(args)...)
+	return &child
+}
+
+// Named returns a child logger whose name is the dot-separated join of the parent name and the given name.
+func (d *DefaultLogger) Named(name string) logapi.Logger {
+	child := *d
+	child.name = name
+	if d.name != "" {
+		child.name = d.name + "." + name
+	}
+	return &child
+}
+
+// WithCallerSkip returns the logger itself, since the default logger does not report the caller
+func (d *DefaultLogger) WithCallerSkip(int) logapi.Logger {
+	return d
 }
 
 func (d *DefaultLogger) Shutdown() error {
-	d.logger.Println(createLog("default logger shutdown", "ERROR"))
+	d.print("ERROR", "default logger shutdown", nil)
+	d.Sync()
 	return nil
 }
 
+// Sync prints the summary of the repetitions of the last record, if any (see WithDedup)
 func (d *DefaultLogger) Sync() {
-	// not needed
+	if d.dedup == nil {
+		return
+	}
+	d.dedup.mu.Lock()
+	defer d.dedup.mu.Unlock()
+	d.flush()
+}
+
+// log prints the record, with the message template rendered (see logapi.RenderTemplate).
+// The template is rendered with the sensitive values redacted, since print only redacts the values matching a pattern in the message.
+func (d *DefaultLogger) log(level string, msg string, args []any) {
+	args = d.normalize(args)
+	fields := d.withFields(args)
+	templateArgs := args
+	if d.redactor != nil {
+		templateArgs = d.redactor.Args(args)
+	}
+	rendered, template := logapi.RenderTemplate(msg, templateArgs)
+	if template {
+		fields = append(fields[:len(fields):len(fields)], logapi.TemplateKey, msg)
+	}
+	d.print(level, rendered, fields)
+}
+
+// print prints the record with the sensitive values redacted, unless it repeats the last one (see WithDedup)
+func (d *DefaultLogger) print(level string, msg string, fields []any) {
+	if d.redactor != nil {
+		msg, _ = d.redactor.String(msg)
+		fields = d.redactor.Args(fields)
+	}
+	if d.dedup == nil {
+		d.logger.Println(createLog(msg, level, fields...))
+		return
+	}
+
+	dd := d.dedup
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+
+	now := time.Now()
+	key := fmt.Sprintf("%s %q%s", level, msg, formatArgs(fields))
+	if collapsible(level) && key == dd.last && now.Sub(dd.first) < dd.window {
+		dd.repeated++
+		dd.lastSeen = now
+		if dd.timer == nil {
+			dd.timer = time.AfterFunc(dd.first.Add(dd.window).Sub(now), d.expire)
+		}
+		return
+	}
+
+	d.flush()
+	dd.last, dd.level, dd.name, dd.first = key, level, d.name, now
+	if !collapsible(level) {
+		dd.last = "" // the next record is printed, even if it is the same
+	}
+	d.logger.Println(createLog(msg, level, fields...))
+}
+
+// expire prints the summary of the repetitions of the last record once its window is over,
+// so that it is not delayed until the next record after a burst
+func (d *DefaultLogger) expire() {
+	dd := d.dedup
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+
+	// a late timer of a record already summarized finds the window of the next one still open
+	if time.Now().Before(dd.first.Add(dd.window)) {
+		return
+	}
+	d.flush()
+}
+
+// flush prints the summary of the repetitions of the last record, if any. The dedup lock must be held.
+func (d *DefaultLogger) flush() {
+	dd := d.dedup
+	if dd.timer != nil {
+		dd.timer.Stop()
+		dd.timer = nil
+	}
+	if dd.repeated > 0 {
+		var fields []any
+		if dd.name != "" {
+			fields = append(fields, "logger", dd.name)
+		}
+		fields = append(fields, "repeated", dd.repeated, "repeat_span", dd.lastSeen.Sub(dd.first))
+		d.logger.Println(createLog(fmt.Sprintf("last message repeated %d times", dd.repeated), dd.level, fields...))
+	}
+	dd.last, dd.repeated = "", 0
+}
+
+// collapsible reports whether the records of the level can be collapsed; the records above ERROR never are
+func collapsible(level string) bool {
+	return level != "DPANIC" && level != "PANIC" && level != "FATAL"
+}
+
+// normalize returns the key-value pairs with the malformed ones fixed (see logapi.NormalizeArgs), counting them
+func (d *DefaultLogger) normalize(args []any) []any {
+	normalized, malformed := logapi.NormalizeArgs(args)
+	if malformed > 0 {
+		d.malformed.Add(uint64(malformed))
+		logapi.ReportMalformed(args, malformed)
+	}
+	return normalized
+}
+
+// MalformedArgs returns the number of malformed key-value arguments given to the logger and its children.
+func (d *DefaultLogger) MalformedArgs() uint64 {
+	return d.malformed.Load()
+}
+
+// withFields prepends the logger name and the bound fields to args, without modifying the bound fields
+func (d *DefaultLogger) withFields(args []any) []any {
+	if len(d.fields) == 0 && d.name == "" {
+		return args
+	}
+
+	all := make([]any, 0, len(d.fields)+len(args)+2)
+	if d.name != "" {
+		all = append(all, "logger", d.name)
+	}
+	all = append(all, d.fields...)
+	return append(all, args...)
 }
 
 func createLog(msg string, level string, args ...any) string {
 	timestamp := time.Now().Format(time.RFC3339)
-	logMsg := fmt.Sprintf("level=%s time=%s msg=%q", level, timestamp, msg)
-
-	if len(args)%2 != 0 {
-		logMsg += "[invalid key-value pairs]"
-	} else {
-		for i := 0; i < len(args); i += 2 {
-			key := fmt.Sprintf("%v", args[i])
-			val := fmt.Sprintf("%v", args[i+1])
-			logMsg += fmt.Sprintf(" %s=%s", key, val)
-		}
+	return fmt.Sprintf("level=%s time=%s msg=%q", level, timestamp, msg) + formatArgs(args)
+}
+
+// formatArgs formats the key-value pairs as ` key=value`, with the control characters escaped so that a value
+// can't forge a record (see logapi.SanitizeText), and quoted if they contain a space, '=' or '"' so that they can't
+// forge other pairs. The pairs are normalized, see logapi.NormalizeArgs
+func formatArgs(args []any) string {
+	var formatted string
+	for i := 0; i+1 < len(args); i += 2 {
+		formatted += fmt.Sprintf(" %s=%s", formatValue(args[i]), formatValue(args[i+1]))
+	}
+	return formatted
+}
+
+// formatValue formats a key or a value of formatArgs
+func formatValue(v any) string {
+	s := fmt.Sprintf("%v", v)
+	if strings.ContainsAny(s, " =\"") {
+		return strconv.Quote(s)
 	}
-	return logMsg
+	return logapi.SanitizeText(s)
 }
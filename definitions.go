@@ -1,6 +1,9 @@
 package common_logger
 
+import "log/slog"
+
 type (
+	// LoggerType identifies a registered logging backend (see Register)
 	LoggerType int
 
 	Config struct {
@@ -9,18 +12,39 @@ type (
 		MaxAge      string
 		LogFile     string
 		LogRotation bool
+		SlogHandler slog.Handler // handler of the Slog logger type; slog.Default() is used if nil
 	}
 )
 
+// The built-in backends. Other backends get their LoggerType from Register.
 const (
 	Zap LoggerType = iota
+	Slog
 )
 
 func (l LoggerType) String() string {
-	switch l {
-	case Zap:
-		return "Zap"
-	default:
+	b, err := lookupBackend(l)
+	if err != nil {
 		return "Unknown"
 	}
+	return b.name
+}
+
+// MarshalText implements encoding.TextMarshaler, so that the logger type can be written in configuration files
+func (l LoggerType) MarshalText() ([]byte, error) {
+	b, err := lookupBackend(l)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(b.name), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so that the logger type can be selected by name in configuration files
+func (l *LoggerType) UnmarshalText(text []byte) error {
+	parsed, err := ParseLoggerType(string(text))
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
 }
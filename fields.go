@@ -0,0 +1,96 @@
+package common_logger
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vlbarou/logger/logapi"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field is an alias of logapi.Field, a typed key-value pair for the hot paths, e.g. InfoF("done", Int("count", n))
+type Field = logapi.Field
+
+func String(key string, value string) Field {
+	return logapi.String(key, value)
+}
+
+func Strings(key string, values []string) Field {
+	return logapi.Strings(key, values)
+}
+
+func Int(key string, value int) Field {
+	return logapi.Int(key, value)
+}
+
+func Int64(key string, value int64) Field {
+	return logapi.Int64(key, value)
+}
+
+func Uint64(key string, value uint64) Field {
+	return logapi.Uint64(key, value)
+}
+
+func Float64(key string, value float64) Field {
+	return logapi.Float64(key, value)
+}
+
+func Bool(key string, value bool) Field {
+	return logapi.Bool(key, value)
+}
+
+func Duration(key string, value time.Duration) Field {
+	return logapi.Duration(key, value)
+}
+
+func Time(key string, value time.Time) Field {
+	return logapi.Time(key, value)
+}
+
+// Err is the error under the "error" key
+func Err(err error) Field {
+	return logapi.Err(err)
+}
+
+func NamedErr(key string, err error) Field {
+	return logapi.NamedErr(key, err)
+}
+
+// Stringer is the value of value.String(), called only if the record is written
+func Stringer(key string, value fmt.Stringer) Field {
+	return logapi.Stringer(key, value)
+}
+
+func Object(key string, value zapcore.ObjectMarshaler) Field {
+	return logapi.Object(key, value)
+}
+
+// Any is the field of the type of value, falling back to reflection
+func Any(key string, value any) Field {
+	return logapi.Any(key, value)
+}
+
+// TraceF logs a trace-level message with typed fields
+func TraceF(msg string, fields ...Field) {
+	global().TraceF(msg, fields...)
+}
+
+// DebugF logs a debug-level message with typed fields
+func DebugF(msg string, fields ...Field) {
+	global().DebugF(msg, fields...)
+}
+
+// InfoF logs an info-level message with typed fields
+func InfoF(msg string, fields ...Field) {
+	global().InfoF(msg, fields...)
+}
+
+// WarnF logs a warn-level message with typed fields
+func WarnF(msg string, fields ...Field) {
+	global().WarnF(msg, fields...)
+}
+
+// ErrorF logs an error-level message with typed fields
+func ErrorF(msg string, fields ...Field) {
+	global().ErrorF(msg, fields...)
+}
@@ -0,0 +1,50 @@
+package common_logger
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vlbarou/logger/default_logger"
+)
+
+func (suite *LoggerTestSuite) TestTypedFields() {
+
+	// arrange
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, nil)
+
+	InfoF("early", Int("count", 1))
+	_, err := GetLogger(Slog, ConfigV2{SlogHandler: handler})
+
+	// act
+	Named("http").InfoF("request served", String("path", "/users"), Int("status", 200), Duration("latency", time.Second))
+	ErrorF("request failed", Err(errors.New("timeout")), Strings("tags", []string{"a", "b"}))
+	DebugF("hidden", Bool("debug", true))
+
+	// assert
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 3, len(lines))
+	assert.Contains(suite.T(), lines[0], `"msg":"early","count":1,"buffered_at"`)
+	assert.Contains(suite.T(), lines[1], `"msg":"request served","logger":"http","path":"/users","status":200,"latency":1000000000`)
+	assert.Contains(suite.T(), lines[2], `"msg":"request failed","error":"timeout","tags":["a","b"]`)
+}
+
+func (suite *LoggerTestSuite) TestDefaultLoggerTypedFields() {
+
+	// arrange
+	var buf bytes.Buffer
+	logger := default_logger.NewWithOutput(&buf)
+
+	// act
+	logger.WarnF("slow request", String("path", "/users"), Duration("latency", time.Second))
+
+	// assert
+	assert.Contains(suite.T(), buf.String(), `level=WARN`)
+	assert.Contains(suite.T(), buf.String(), `msg="slow request" path=/users latency=1s`)
+}
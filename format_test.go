@@ -0,0 +1,65 @@
+package common_logger
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vlbarou/logger/default_logger"
+)
+
+func (suite *LoggerTestSuite) TestPrintfAndLazyVariants() {
+
+	// arrange
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo, AddSource: true})
+
+	built := 0
+	payload := func() (string, []any) {
+		built++
+		return "lazy", []any{"payload", "expensive"}
+	}
+
+	Infof("early %s", "info")
+	_, err := GetLogger(Slog, ConfigV2{SlogHandler: handler})
+
+	// act
+	_, _, infofLine, _ := runtime.Caller(0)
+	Infof("user %s bought %d items", "bob", 3)
+	Debugf("hidden %s", "debug")
+	DebugFn(payload)
+	WarnFn(payload)
+
+	// assert
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, built)
+	assert.True(suite.T(), Enabled(InfoLevel))
+	assert.False(suite.T(), Enabled(DebugLevel))
+	assert.Equal(suite.T(), 3, len(lines))
+	assert.Contains(suite.T(), lines[0], `"msg":"early info"`)
+	assert.Contains(suite.T(), lines[1], `"msg":"user bob bought 3 items"`)
+	assert.Contains(suite.T(), lines[1], fmt.Sprintf(`"line":%d}`, infofLine+1))
+	assert.Contains(suite.T(), lines[2], `"msg":"lazy","payload":"expensive"`)
+}
+
+func (suite *LoggerTestSuite) TestDefaultLoggerPrintfAndLazyVariants() {
+
+	// arrange
+	var buf bytes.Buffer
+	logger := default_logger.NewWithOutput(&buf).With("key", "value")
+
+	// act
+	logger.Errorf("%d failures", 2)
+	logger.InfoFn(func() (string, []any) { return "lazy", []any{"payload", "expensive"} })
+
+	// assert
+	assert.True(suite.T(), logger.Enabled(TraceLevel))
+	assert.Contains(suite.T(), buf.String(), `level=ERROR`)
+	assert.Contains(suite.T(), buf.String(), `msg="2 failures" key=value`)
+	assert.Contains(suite.T(), buf.String(), `msg="lazy" key=value payload=expensive`)
+}
@@ -0,0 +1,43 @@
+package common_logger
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	instancesMu sync.RWMutex
+	instances   = make(map[string]Logger)
+)
+
+// RegisterLogger makes a logger (typically created with NewLogger) available under the given name with Get,
+// e.g. RegisterLogger("audit", auditLogger). It fails if the name is already taken.
+func RegisterLogger(name string, logger Logger) error {
+	instancesMu.Lock()
+	defer instancesMu.Unlock()
+
+	if _, ok := instances[name]; ok {
+		return fmt.Errorf("a logger is already registered as %q", name)
+	}
+	instances[name] = logger
+	return nil
+}
+
+// UnregisterLogger removes the logger registered under the given name and returns it, so that it can be shut down
+func UnregisterLogger(name string) (Logger, bool) {
+	instancesMu.Lock()
+	defer instancesMu.Unlock()
+
+	l, ok := instances[name]
+	delete(instances, name)
+	return l, ok
+}
+
+// Get returns the logger registered under the given name, e.g. Get("audit")
+func Get(name string) (Logger, bool) {
+	instancesMu.RLock()
+	defer instancesMu.RUnlock()
+
+	l, ok := instances[name]
+	return l, ok
+}
@@ -0,0 +1,69 @@
+package common_logger
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vlbarou/logger/mocks"
+)
+
+func (suite *LoggerTestSuite) TestNewLoggerInstancesAreIsolated() {
+
+	var err error
+
+	// arrange
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+	auditFile := filepath.Join(suite.tempDir, "audit.log")
+
+	_, err = GetLogger(Zap, ConfigV2{LogFile: suite.tempLogFile.Name(), Outputs: []string{OutputFile}})
+	audit, auditErr := NewLogger(Zap, ConfigV2{LogFile: auditFile, Outputs: []string{OutputFile}})
+
+	// act
+	Info("main record")
+	audit.Info("audit record")
+	assert.Nil(suite.T(), audit.Shutdown())
+	assert.Nil(suite.T(), Shutdown())
+
+	// assert
+	mainContent, _ := os.ReadFile(suite.tempLogFile.Name())
+	auditContent, _ := os.ReadFile(auditFile)
+
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), auditErr)
+	assert.NotSame(suite.T(), instance(), audit)
+	assert.Contains(suite.T(), string(mainContent), "main record")
+	assert.NotContains(suite.T(), string(mainContent), "audit record")
+	assert.Contains(suite.T(), string(auditContent), "audit record")
+	assert.NotContains(suite.T(), string(auditContent), "main record")
+}
+
+func (suite *LoggerTestSuite) TestNewLoggerWithInvalidConfig() {
+
+	// act
+	l, err := NewLogger(Zap, ConfigV2{MaxBackups: -1})
+
+	// assert
+	assert.Nil(suite.T(), l)
+	assert.ErrorContains(suite.T(), err, "MaxBackups: must not be negative")
+}
+
+func (suite *LoggerTestSuite) TestRegisterLogger() {
+
+	// arrange
+	audit := new(mocks.Logger)
+	defer UnregisterLogger("audit")
+
+	// act
+	err := RegisterLogger("audit", audit)
+	duplicateErr := RegisterLogger("audit", new(mocks.Logger))
+	l, ok := Get("audit")
+	_, missing := Get("missing")
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.ErrorContains(suite.T(), duplicateErr, `a logger is already registered as "audit"`)
+	assert.True(suite.T(), ok)
+	assert.Same(suite.T(), audit, l)
+	assert.False(suite.T(), missing)
+}
@@ -0,0 +1,108 @@
+package common_logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vlbarou/logger/default_logger"
+	"github.com/vlbarou/logger/slogLogger"
+)
+
+func (suite *LoggerTestSuite) TestParseLevel() {
+
+	for text, expected := range map[string]Level{
+		"trace": TraceLevel, "": InfoLevel, "WARNING": WarnLevel, "dpanic": DPanicLevel, "panic": PanicLevel, "fatal": FatalLevel,
+	} {
+		level, err := ParseLevel(text)
+		assert.Nil(suite.T(), err, text)
+		assert.Equal(suite.T(), expected, level, text)
+	}
+
+	_, err := ParseLevel("verbose")
+	assert.NotNil(suite.T(), err)
+}
+
+func (suite *LoggerTestSuite) TestFatalCallsTheExitFunc() {
+
+	// arrange
+	var buf bytes.Buffer
+	exitCode := -1
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slogLogger.LevelTrace})
+
+	_, err := GetLogger(Slog, ConfigV2{SlogHandler: handler, ExitFunc: func(code int) { exitCode = code }})
+
+	// act
+	Trace("trace")
+	FatalCtx(context.Background(), "fatal", "key", "value")
+
+	// assert
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, exitCode)
+	assert.Equal(suite.T(), 2, len(lines))
+	assert.Contains(suite.T(), lines[0], `"level":"DEBUG-4","msg":"trace"`)
+	assert.Contains(suite.T(), lines[1], `"level":"ERROR+12","msg":"fatal","key":"value"`)
+}
+
+func (suite *LoggerTestSuite) TestPanicAndDPanic() {
+
+	// arrange
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, nil)
+
+	_, err := GetLogger(Slog, ConfigV2{SlogHandler: handler})
+
+	// act & assert
+	assert.Nil(suite.T(), err)
+	assert.Panics(suite.T(), func() { Panic("panic") })
+	assert.NotPanics(suite.T(), func() { DPanic("dpanic") })
+	assert.Equal(suite.T(), 2, strings.Count(buf.String(), "\n"))
+
+	development := slogLogger.New(handler).WithDevelopment(true)
+	assert.Panics(suite.T(), func() { development.DPanic("dpanic in development") })
+}
+
+func (suite *LoggerTestSuite) TestPanicsBeforeInitializationAreReplayedAtErrorLevel() {
+
+	// arrange
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, nil)
+
+	assert.Panics(suite.T(), func() { Panic("early panic") })
+	DPanic("early dpanic")
+
+	// act
+	_, err := GetLogger(Slog, ConfigV2{SlogHandler: handler})
+
+	// assert
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(lines))
+	assert.Contains(suite.T(), lines[0], `"level":"ERROR","msg":"early panic"`)
+	assert.Contains(suite.T(), lines[0], `"buffered_level":"panic"`)
+	assert.Contains(suite.T(), lines[1], `"buffered_level":"dpanic"`)
+}
+
+func (suite *LoggerTestSuite) TestDefaultLoggerFatal() {
+
+	// arrange
+	var buf bytes.Buffer
+	exitCode := -1
+	logger := default_logger.NewWithOutput(&buf).WithExitFunc(func(code int) { exitCode = code })
+
+	// act
+	logger.Trace("trace")
+	logger.Named("db").Fatal("fatal", "key", "value")
+
+	// assert
+	assert.Equal(suite.T(), 1, exitCode)
+	assert.Contains(suite.T(), buf.String(), `level=TRACE`)
+	assert.Contains(suite.T(), buf.String(), `level=FATAL`)
+	assert.Contains(suite.T(), buf.String(), `msg="fatal" logger=db key=value`)
+	assert.Panics(suite.T(), func() { logger.Panic("panic") })
+}
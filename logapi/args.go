@@ -0,0 +1,141 @@
+package logapi
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// The keys of the malformed key-value pairs, which are logged instead of being dropped
+const (
+	BadKey   = "!BADKEY" // holds a key that is not a string, e.g. Info("msg", 42, "value") logs !BADKEY=42 and !EXTRA=value
+	ExtraKey = "!EXTRA"  // holds a trailing argument without value, e.g. Info("msg", "key") logs !EXTRA=key
+)
+
+// ErrorKey is the key of an error passed without key, e.g. Error("failed", err), as zap.Error does
+const ErrorKey = "error"
+
+// StrictMode is the handling of the malformed key-value pairs, on top of logging them under BadKey and ExtraKey
+type StrictMode int32
+
+const (
+	StrictOff    StrictMode = iota // the malformed pairs are only logged and counted
+	StrictReport                   // the call site is also reported on stderr
+	StrictPanic                    // the call panics with a *MalformedArgsError, e.g. in tests
+)
+
+var strictMode atomic.Int32
+
+// SetStrictMode sets the handling of the malformed key-value pairs, for every logger of the process
+func SetStrictMode(mode StrictMode) {
+	strictMode.Store(int32(mode))
+}
+
+// MalformedArgsError describes a logging call with malformed key-value pairs
+type MalformedArgsError struct {
+	Caller    string // file:line of the logging call
+	Args      []any  // the key-value pairs, as given
+	Malformed int    // number of malformed arguments
+}
+
+func (e *MalformedArgsError) Error() string {
+	return fmt.Sprintf("%s: %d malformed key-value arguments in %v", e.Caller, e.Malformed, e.Args)
+}
+
+// MalformedArgsCounter is implemented by the loggers that count the malformed key-value arguments they are given
+type MalformedArgsCounter interface {
+	MalformedArgs() uint64
+}
+
+// NormalizeArgs returns the key-value pairs with string keys only, and the number of malformed arguments:
+//   - an error without key is logged under ErrorKey;
+//   - an slog.Attr is expanded to its key and value;
+//   - a key that is not a string is logged under BadKey, and the next argument is the next key;
+//   - a trailing argument is logged under ExtraKey.
+//
+// Well-formed args are returned as they are, without allocating. The loggers call ReportMalformed if any is malformed.
+func NormalizeArgs(args []any) ([]any, int) {
+	if wellFormed(args) {
+		return args, 0
+	}
+
+	normalized := make([]any, 0, len(args)+2)
+	malformed := 0
+	for i := 0; i < len(args); {
+		switch key := args[i].(type) {
+		case string:
+			if i == len(args)-1 {
+				normalized = append(normalized, ExtraKey, key)
+				malformed++
+				i++
+				continue
+			}
+			normalized = append(normalized, key, args[i+1])
+			i += 2
+		case error:
+			normalized = append(normalized, ErrorKey, key)
+			i++
+		case slog.Attr:
+			normalized = append(normalized, key.Key, key.Value)
+			i++
+		default:
+			normalized = append(normalized, BadKey, key)
+			malformed++
+			i++
+		}
+	}
+
+	return normalized, malformed
+}
+
+// wellFormed reports whether args are pairs with string keys
+func wellFormed(args []any) bool {
+	if len(args)%2 != 0 {
+		return false
+	}
+	for i := 0; i < len(args); i += 2 {
+		if _, ok := args[i].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ReportMalformed handles the malformed arguments of a logging call according to the strict mode
+func ReportMalformed(args []any, malformed int) {
+	mode := StrictMode(strictMode.Load())
+	if mode == StrictOff {
+		return
+	}
+
+	err := &MalformedArgsError{Caller: caller(), Args: args, Malformed: malformed}
+	if mode == StrictPanic {
+		panic(err)
+	}
+	fmt.Fprintln(os.Stderr, err)
+}
+
+// modulePath is the import path of this module, whose frames are skipped to find the logging call
+var modulePath = strings.TrimSuffix(reflect.TypeOf(MalformedArgsError{}).PkgPath(), "/logapi")
+
+// caller returns the file:line of the first frame outside of this module, ignoring its tests
+func caller() string {
+	var pcs [32]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	for {
+		frame, more := frames.Next()
+		if !inModule(frame.Function) || strings.HasSuffix(frame.File, "_test.go") || !more {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+	}
+}
+
+// inModule reports whether the function belongs to a package of this module
+func inModule(function string) bool {
+	rest, ok := strings.CutPrefix(function, modulePath)
+	return ok && (strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "/"))
+}
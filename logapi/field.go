@@ -0,0 +1,92 @@
+package logapi
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field is a typed key-value pair, for the hot paths (see InfoF). It is the zap field, so that the zap logger
+// writes it as is, without boxing nor reflection; the other backends convert it with FieldValue.
+type Field = zapcore.Field
+
+func String(key string, value string) Field {
+	return zap.String(key, value)
+}
+
+func Strings(key string, values []string) Field {
+	return zap.Strings(key, values)
+}
+
+func Int(key string, value int) Field {
+	return zap.Int(key, value)
+}
+
+func Int64(key string, value int64) Field {
+	return zap.Int64(key, value)
+}
+
+func Uint64(key string, value uint64) Field {
+	return zap.Uint64(key, value)
+}
+
+func Float64(key string, value float64) Field {
+	return zap.Float64(key, value)
+}
+
+func Bool(key string, value bool) Field {
+	return zap.Bool(key, value)
+}
+
+func Duration(key string, value time.Duration) Field {
+	return zap.Duration(key, value)
+}
+
+func Time(key string, value time.Time) Field {
+	return zap.Time(key, value)
+}
+
+// Err is the error under ErrorKey, e.g. Err(err)
+func Err(err error) Field {
+	return zap.Error(err)
+}
+
+func NamedErr(key string, err error) Field {
+	return zap.NamedError(key, err)
+}
+
+// Stringer is the value of value.String(), called only if the record is written
+func Stringer(key string, value fmt.Stringer) Field {
+	return zap.Stringer(key, value)
+}
+
+func Object(key string, value zapcore.ObjectMarshaler) Field {
+	return zap.Object(key, value)
+}
+
+// Any is the field of the type of value, falling back to reflection
+func Any(key string, value any) Field {
+	return zap.Any(key, value)
+}
+
+// FieldValue returns the value of the field, as encoded by zap, for the backends that take key-value pairs.
+// Objects are maps, and errors are their message.
+func FieldValue(f Field) any {
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	return enc.Fields[f.Key]
+}
+
+// FieldArgs returns the key-value pairs of the fields
+func FieldArgs(fields []Field) []any {
+	args := make([]any, 0, 2*len(fields))
+	for _, f := range fields {
+		if f.Type == zapcore.SkipType {
+			continue
+		}
+		args = append(args, f.Key, FieldValue(f))
+	}
+	return args
+}
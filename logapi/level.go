@@ -0,0 +1,87 @@
+package logapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is the severity of a record. The values match the zapcore levels; TraceLevel is a custom zapcore level.
+type Level int8
+
+const (
+	TraceLevel Level = iota - 2
+	DebugLevel
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+	DPanicLevel // panics in development mode, see zap's DPanic
+	PanicLevel  // panics after logging
+	FatalLevel  // exits after logging
+)
+
+func (l Level) String() string {
+	switch l {
+	case TraceLevel:
+		return "trace"
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case DPanicLevel:
+		return "dpanic"
+	case PanicLevel:
+		return "panic"
+	case FatalLevel:
+		return "fatal"
+	default:
+		return fmt.Sprintf("Level(%d)", l)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Level names are case-insensitive
+func (l *Level) UnmarshalText(text []byte) error {
+	parsed, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
+
+// ParseLevel returns the level of the given (case-insensitive) name, e.g. "debug"
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "trace":
+		return TraceLevel, nil
+	case "debug":
+		return DebugLevel, nil
+	case "info", "":
+		return InfoLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "error":
+		return ErrorLevel, nil
+	case "dpanic":
+		return DPanicLevel, nil
+	case "panic":
+		return PanicLevel, nil
+	case "fatal":
+		return FatalLevel, nil
+	default:
+		return InfoLevel, fmt.Errorf("unknown level %q", name)
+	}
+}
+
+// IsValid reports whether the level is one of the defined levels
+func (l Level) IsValid() bool {
+	return l >= TraceLevel && l <= FatalLevel
+}
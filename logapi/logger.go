@@ -0,0 +1,61 @@
+package logapi
+
+// Logger is the contract implemented by every logging backend.
+// It lives in its own package so that the backends can return derived loggers
+// without importing the `common_logger` package (which in turn imports the backends).
+type Logger interface {
+	Trace(message string, args ...any)
+	Info(message string, args ...any)
+	Debug(message string, args ...any)
+	Error(message string, args ...any)
+	Warn(message string, args ...any)
+	DPanic(message string, args ...any) // panics in development mode only
+	Panic(message string, args ...any)  // panics after logging
+	Fatal(message string, args ...any)  // flushes the outputs and exits after logging
+
+	// printf-style variants; the message is only formatted when the level is enabled
+	Tracef(format string, args ...any)
+	Debugf(format string, args ...any)
+	Infof(format string, args ...any)
+	Warnf(format string, args ...any)
+	Errorf(format string, args ...any)
+
+	// lazy variants; the function is only called when the level is enabled
+	TraceFn(fn MessageFunc)
+	DebugFn(fn MessageFunc)
+	InfoFn(fn MessageFunc)
+	WarnFn(fn MessageFunc)
+	ErrorFn(fn MessageFunc)
+
+	// typed variants, for the hot paths; the messages are not rendered as templates
+	TraceF(message string, fields ...Field)
+	DebugF(message string, fields ...Field)
+	InfoF(message string, fields ...Field)
+	WarnF(message string, fields ...Field)
+	ErrorF(message string, fields ...Field)
+
+	// Enabled reports whether a record of the given level would be logged
+	Enabled(level Level) bool
+
+	With(args ...any) Logger
+	Named(name string) Logger
+	Shutdown() error
+	Sync()
+}
+
+// MessageFunc builds the message and the key-value pairs of a record, e.g. from an expensive payload
+type MessageFunc func() (message string, args []any)
+
+// CallerSkipper is implemented by the loggers that report the caller. Callers that wrap a logger in their own
+// functions use WithCallerSkip to skip their frames, so that the reported caller is the caller of the wrapper.
+type CallerSkipper interface {
+	WithCallerSkip(skip int) Logger
+}
+
+// WithCallerSkip returns the logger with skip more frames skipped, or the logger itself if it does not report the caller
+func WithCallerSkip(l Logger, skip int) Logger {
+	if s, ok := l.(CallerSkipper); ok {
+		return s.WithCallerSkip(skip)
+	}
+	return l
+}
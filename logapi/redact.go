@@ -0,0 +1,339 @@
+package logapi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// RedactMode is what the redaction does with a sensitive value
+type RedactMode int
+
+const (
+	RedactMask RedactMode = iota // the value is replaced by RedactedMask
+	RedactHash                   // the value is replaced by its hash, so that equal values can still be correlated
+	RedactDrop                   // the field is removed; the sensitive values found in the messages are masked
+)
+
+func (m RedactMode) String() string {
+	switch m {
+	case RedactMask:
+		return "mask"
+	case RedactHash:
+		return "hash"
+	case RedactDrop:
+		return "drop"
+	default:
+		return fmt.Sprintf("RedactMode(%d)", int(m))
+	}
+}
+
+// IsValid reports whether m is one of the defined modes
+func (m RedactMode) IsValid() bool {
+	return m >= RedactMask && m <= RedactDrop
+}
+
+// RedactedMask replaces the sensitive values in RedactMask mode
+const RedactedMask = "***"
+
+// Redactable is implemented by the types that know which of their fields are sensitive:
+// Redacted returns the value to log instead, e.g. a copy without the credentials
+type Redactable interface {
+	Redacted() any
+}
+
+// ValuePattern matches the sensitive values wherever they appear, e.g. an email address in a request payload
+type ValuePattern struct {
+	Name   string
+	Regexp *regexp.Regexp
+	Valid  func(match string) bool // optional check of the matches, e.g. the Luhn checksum of the card numbers
+}
+
+// The built-in value patterns
+var (
+	EmailPattern = ValuePattern{Name: "email", Regexp: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)}
+	JWTPattern   = ValuePattern{Name: "jwt", Regexp: regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)}
+	PANPattern   = ValuePattern{Name: "pan", Regexp: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), Valid: luhnValid}
+)
+
+// RedactionConfig selects the sensitive values. Redaction is disabled if there are no keys and no patterns.
+type RedactionConfig struct {
+	Keys     []string       // case-insensitive names or globs (see path.Match) of the keys of the sensitive values, e.g. "password", "*_token"
+	Patterns []ValuePattern // patterns of the sensitive values, in the strings of the fields at any depth and in the messages
+	Mode     RedactMode
+	HashKey  []byte // in RedactHash mode, the values are hashed with HMAC-SHA256 and this key, so that they can't be guessed
+}
+
+// DefaultRedaction returns a configuration that masks the usual credentials, and the emails, JWTs and card numbers
+func DefaultRedaction() RedactionConfig {
+	return RedactionConfig{
+		Keys:     []string{"password", "passwd", "secret", "*_secret", "token", "*_token", "authorization", "cookie", "api_key", "apikey"},
+		Patterns: []ValuePattern{EmailPattern, JWTPattern, PANPattern},
+	}
+}
+
+// Redactor applies a RedactionConfig to the records. It is safe for concurrent use.
+type Redactor struct {
+	names    map[string]struct{}
+	globs    []string
+	patterns []ValuePattern
+	mode     RedactMode
+	hashKey  []byte
+}
+
+// NewRedactor compiles the configuration. It returns nil if the configuration redacts nothing.
+func NewRedactor(config RedactionConfig) (*Redactor, error) {
+	if !config.Mode.IsValid() {
+		return nil, fmt.Errorf("unknown mode %s", config.Mode)
+	}
+	if len(config.Keys) == 0 && len(config.Patterns) == 0 {
+		return nil, nil
+	}
+
+	r := &Redactor{names: make(map[string]struct{}), mode: config.Mode, hashKey: config.HashKey}
+	for _, key := range config.Keys {
+		key = strings.ToLower(key)
+		if !strings.ContainsAny(key, `*?[\`) {
+			r.names[key] = struct{}{}
+			continue
+		}
+		if _, err := path.Match(key, ""); err != nil {
+			return nil, fmt.Errorf("invalid key glob %q: %w", key, err)
+		}
+		r.globs = append(r.globs, key)
+	}
+	for _, pattern := range config.Patterns {
+		if pattern.Regexp == nil {
+			return nil, fmt.Errorf("pattern %q has no regexp", pattern.Name)
+		}
+		r.patterns = append(r.patterns, pattern)
+	}
+	return r, nil
+}
+
+// Mode returns the redaction mode
+func (r *Redactor) Mode() RedactMode {
+	return r.mode
+}
+
+// SensitiveKey reports whether the values of the key are redacted as a whole
+func (r *Redactor) SensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if _, ok := r.names[key]; ok {
+		return true
+	}
+	for _, glob := range r.globs {
+		if ok, _ := path.Match(glob, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Replace returns the replacement of a sensitive value: RedactedMask, or its hash in RedactHash mode
+func (r *Redactor) Replace(value string) string {
+	if r.mode != RedactHash {
+		return RedactedMask
+	}
+
+	var h hash.Hash
+	if len(r.hashKey) > 0 {
+		h = hmac.New(sha256.New, r.hashKey)
+	} else {
+		h = sha256.New()
+	}
+	h.Write([]byte(value))
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// String replaces the sensitive values found in s by the value patterns, and reports whether there were any
+func (r *Redactor) String(s string) (string, bool) {
+	found := false
+	for _, pattern := range r.patterns {
+		s = pattern.Regexp.ReplaceAllStringFunc(s, func(match string) string {
+			if pattern.Valid != nil && !pattern.Valid(match) {
+				return match
+			}
+			found = true
+			return r.Replace(match)
+		})
+	}
+	return s, found
+}
+
+// Value returns the value of the key with the sensitive values redacted, walking the maps, structs and slices
+// as they are encoded in JSON. The result is false if the value must be dropped (RedactDrop mode).
+func (r *Redactor) Value(key string, value any) (any, bool) {
+	return r.value(key, value, 0)
+}
+
+// maxRedactDepth bounds the walk of the values, which may be cyclic
+const maxRedactDepth = 32
+
+func (r *Redactor) value(key string, value any, depth int) (any, bool) {
+	if r.SensitiveKey(key) {
+		if r.mode == RedactDrop {
+			return nil, false
+		}
+		return r.Replace(fmt.Sprint(value)), true
+	}
+	return r.walk(value, depth)
+}
+
+// Walk redacts the strings and the sensitive keys nested in the value, see Value
+func (r *Redactor) Walk(value any) (any, bool) {
+	return r.walk(value, 0)
+}
+
+// Args returns the key-value pairs with the sensitive values redacted. The pairs are expected to be normalized (see NormalizeArgs)
+func (r *Redactor) Args(args []any) []any {
+	redacted := make([]any, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		key, _ := args[i].(string)
+		if value, keep := r.Value(key, args[i+1]); keep {
+			redacted = append(redacted, args[i], value)
+		}
+	}
+	return redacted
+}
+
+// walk redacts the strings and the sensitive keys of a value
+func (r *Redactor) walk(value any, depth int) (any, bool) {
+	if depth > maxRedactDepth {
+		return RedactedMask, true
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, true
+	case Redactable:
+		return r.walk(v.Redacted(), depth+1)
+	case string:
+		return r.redactString(v, v)
+	case error:
+		return r.redactString(v.Error(), v)
+	case json.Marshaler:
+		b, err := v.MarshalJSON()
+		var decoded any
+		if err != nil || json.Unmarshal(b, &decoded) != nil {
+			return value, true
+		}
+		return r.walk(decoded, depth+1)
+	case encoding.TextMarshaler:
+		b, err := v.MarshalText()
+		if err != nil {
+			return value, true
+		}
+		return r.redactString(string(b), string(b))
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil, true
+		}
+		return r.walk(rv.Elem().Interface(), depth+1)
+	case reflect.Map:
+		if rv.IsNil() {
+			return value, true
+		}
+		redacted := make(map[string]any, rv.Len())
+		for it := rv.MapRange(); it.Next(); {
+			key := fmt.Sprint(it.Key().Interface())
+			if v, keep := r.value(key, it.Value().Interface(), depth+1); keep {
+				redacted[key] = v
+			}
+		}
+		return redacted, true
+	case reflect.Struct:
+		return r.walkStruct(rv, depth+1), true
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && (rv.IsNil() || rv.Type().Elem().Kind() == reflect.Uint8) {
+			return value, true // JSON encodes the bytes in base64
+		}
+		redacted := make([]any, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			if v, keep := r.walk(rv.Index(i).Interface(), depth+1); keep {
+				redacted = append(redacted, v)
+			}
+		}
+		return redacted, true
+	default:
+		return value, true
+	}
+}
+
+// walkStruct returns the exported fields of a struct as a map, named after their JSON tags
+func (r *Redactor) walkStruct(rv reflect.Value, depth int) map[string]any {
+	redacted := make(map[string]any, rv.NumField())
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		value := rv.Field(i)
+		if strings.Contains(options, "omitempty") && value.IsZero() {
+			continue
+		}
+		if field.Anonymous && name == "" && value.Kind() == reflect.Struct {
+			for k, v := range r.walkStruct(value, depth) {
+				redacted[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if v, keep := r.value(name, value.Interface(), depth); keep {
+			redacted[name] = v
+		}
+	}
+	return redacted
+}
+
+// redactString returns the string with the sensitive values redacted, or the original value if there were none
+func (r *Redactor) redactString(s string, original any) (any, bool) {
+	redacted, found := r.String(s)
+	if !found {
+		return original, true
+	}
+	if r.mode == RedactDrop {
+		return nil, false
+	}
+	return redacted, true
+}
+
+// luhnValid reports whether the digits of s pass the Luhn checksum of the card numbers
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
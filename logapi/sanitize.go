@@ -0,0 +1,75 @@
+package logapi
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SanitizeText escapes the characters that could forge records or terminal output in the text formats:
+// the line breaks, the ANSI escape sequences and the other control characters, the Unicode line separators
+// and bidirectional overrides, and the invalid UTF-8 bytes. They are written as Go escapes, e.g. \n, \x1b or \u2028.
+// Text without such characters is returned as is, without allocating.
+func SanitizeText(s string) string {
+	i := unsafeIndex(s)
+	if i < 0 {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	b.WriteString(s[:i])
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\x%02x`, s[i])
+		case UnsafeRune(r):
+			b.WriteString(escapeRune(r))
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// unsafeIndex returns the index of the first character to escape, or -1
+func unsafeIndex(s string) int {
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= 0x20 && c < 0x7f {
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r == utf8.RuneError && size == 1) || UnsafeRune(r) {
+			return i
+		}
+		i += size
+	}
+	return -1
+}
+
+// UnsafeRune reports whether r must be escaped in the text formats, see SanitizeText
+func UnsafeRune(r rune) bool {
+	return unicode.IsControl(r) ||
+		r == '\u2028' || r == '\u2029' || // line and paragraph separators
+		(r >= '\u202a' && r <= '\u202e') || (r >= '\u2066' && r <= '\u2069') // bidirectional overrides and isolates
+}
+
+func escapeRune(r rune) string {
+	switch r {
+	case '\n':
+		return `\n`
+	case '\r':
+		return `\r`
+	case '\t':
+		return `\t`
+	}
+	if r < utf8.RuneSelf {
+		return fmt.Sprintf(`\x%02x`, r)
+	}
+	return fmt.Sprintf(`\u%04x`, r)
+}
@@ -0,0 +1,92 @@
+package logapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TemplateKey is the key of the field holding the template of a rendered message, e.g. "User {user} logged in",
+// so that the records of the same template can be grouped
+const TemplateKey = "message_template"
+
+// RenderTemplate replaces the {name} placeholders of the message with the values of the matching key-value pairs
+// of args, e.g. RenderTemplate("User {user} logged in", []any{"user", "bob"}) returns "User bob logged in".
+// Names are made of letters, digits, '_', '.' and '-', and don't start with a digit or a punctuation character.
+// "{{" and "}}" are escaped braces. Placeholders without a matching argument are kept as they are.
+// If the message has no placeholder it is returned unchanged, escapes included (e.g. JSON), along with false.
+func RenderTemplate(message string, args []any) (string, bool) {
+	if strings.IndexByte(message, '{') < 0 {
+		return message, false
+	}
+
+	var b strings.Builder
+	b.Grow(len(message))
+	found := false
+
+	for i := 0; i < len(message); {
+		c := message[i]
+		switch {
+		case c == '{' && i+1 < len(message) && message[i+1] == '{':
+			b.WriteByte('{')
+			i += 2
+		case c == '}' && i+1 < len(message) && message[i+1] == '}':
+			b.WriteByte('}')
+			i += 2
+		case c == '{':
+			end := placeholderEnd(message, i+1)
+			if end < 0 {
+				b.WriteByte(c)
+				i++
+				continue
+			}
+
+			found = true
+			if value, ok := lookup(args, message[i+1:end]); ok {
+				b.WriteString(fmt.Sprint(value))
+			} else {
+				b.WriteString(message[i : end+1])
+			}
+			i = end + 1
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	if !found {
+		return message, false
+	}
+	return b.String(), true
+}
+
+// placeholderEnd returns the index of the '}' closing the placeholder name starting at start, or -1 if there is none
+func placeholderEnd(message string, start int) int {
+	for i := start; i < len(message); i++ {
+		c := message[i]
+		switch {
+		case c == '}':
+			if i == start {
+				return -1
+			}
+			return i
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		case (c >= '0' && c <= '9') || c == '.' || c == '-':
+			if i == start {
+				return -1
+			}
+		default:
+			return -1
+		}
+	}
+	return -1
+}
+
+// lookup returns the value of the first key-value pair of args with the given key
+func lookup(args []any, key string) (any, bool) {
+	for i := 0; i+1 < len(args); i += 2 {
+		if k, ok := args[i].(string); ok && k == key {
+			return args[i+1], true
+		}
+	}
+	return nil, false
+}
@@ -1,10 +1,78 @@
 package common_logger
 
-type Logger interface {
-	Info(message string, args ...any)
-	Debug(message string, args ...any)
-	Error(message string, args ...any)
-	Warn(message string, args ...any)
-	Shutdown() error
-	Sync()
+import (
+	"net"
+
+	"github.com/vlbarou/logger/logapi"
+	"github.com/vlbarou/logger/zapLogger"
+)
+
+// Logger is an alias of logapi.Logger, so that derived loggers returned by the backends
+// (e.g. by `With`) can be used wherever a common_logger.Logger is expected
+type Logger = logapi.Logger
+
+// Level is an alias of logapi.Level, the severity of a record
+type Level = logapi.Level
+
+// MessageFunc is an alias of logapi.MessageFunc, the builder of the lazy records (e.g. of DebugFn)
+type MessageFunc = logapi.MessageFunc
+
+const (
+	TraceLevel  = logapi.TraceLevel
+	DebugLevel  = logapi.DebugLevel
+	InfoLevel   = logapi.InfoLevel
+	WarnLevel   = logapi.WarnLevel
+	ErrorLevel  = logapi.ErrorLevel
+	DPanicLevel = logapi.DPanicLevel
+	PanicLevel  = logapi.PanicLevel
+	FatalLevel  = logapi.FatalLevel
+)
+
+// ParseLevel returns the level of the given (case-insensitive) name, e.g. "debug"
+func ParseLevel(name string) (Level, error) {
+	return logapi.ParseLevel(name)
+}
+
+// StrictMode is an alias of logapi.StrictMode, the handling of the malformed key-value arguments
+type StrictMode = logapi.StrictMode
+
+const (
+	StrictOff    = logapi.StrictOff    // the malformed arguments are logged under "!BADKEY" and "!EXTRA", and counted
+	StrictReport = logapi.StrictReport // the call site is also reported on stderr
+	StrictPanic  = logapi.StrictPanic  // the call panics with a *logapi.MalformedArgsError, e.g. in tests
+)
+
+// SetStrictMode sets the handling of the malformed key-value arguments, for every logger of the process
+func SetStrictMode(mode StrictMode) {
+	logapi.SetStrictMode(mode)
+}
+
+// MalformedArgs returns the number of malformed key-value arguments given to the global logger and its children,
+// or 0 if its backend doesn't count them
+func MalformedArgs() uint64 {
+	if c, ok := instance().(logapi.MalformedArgsCounter); ok {
+		return c.MalformedArgs()
+	}
+	return 0
+}
+
+// AsyncStats are the counters of the asynchronous writes, see AsyncConfig
+type AsyncStats = zapLogger.AsyncStats
+
+// AsyncCounters returns the counters of the asynchronous writes of the global logger,
+// or zero counters if its backend doesn't write asynchronously
+func AsyncCounters() AsyncStats {
+	if l, ok := instance().(interface{ AsyncStats() AsyncStats }); ok {
+		return l.AsyncStats()
+	}
+	return AsyncStats{}
+}
+
+// AdminAddr returns the address the log server of the global logger listens on, e.g. to find the port chosen
+// for AdminPort "0", or nil if it has no log server
+func AdminAddr() net.Addr {
+	if l, ok := instance().(interface{ AdminAddr() net.Addr }); ok {
+		return l.AdminAddr()
+	}
+	return nil
 }
@@ -2,13 +2,26 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	mock "github.com/stretchr/testify/mock"
+	logapi "github.com/vlbarou/logger/logapi"
+
+	zapcore "go.uber.org/zap/zapcore"
+)
 
 // Logger is an autogenerated mock type for the Logger type
 type Logger struct {
 	mock.Mock
 }
 
+// DPanic provides a mock function with given fields: message, args
+func (_m *Logger) DPanic(message string, args ...interface{}) {
+	var _ca []interface{}
+	_ca = append(_ca, message)
+	_ca = append(_ca, args...)
+	_m.Called(_ca...)
+}
+
 // Debug provides a mock function with given fields: message, args
 func (_m *Logger) Debug(message string, args ...interface{}) {
 	var _ca []interface{}
@@ -17,6 +30,49 @@ func (_m *Logger) Debug(message string, args ...interface{}) {
 	_m.Called(_ca...)
 }
 
+// DebugF provides a mock function with given fields: message, fields
+func (_m *Logger) DebugF(message string, fields ...zapcore.Field) {
+	_va := make([]interface{}, len(fields))
+	for _i := range fields {
+		_va[_i] = fields[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, message)
+	_ca = append(_ca, _va...)
+	_m.Called(_ca...)
+}
+
+// DebugFn provides a mock function with given fields: fn
+func (_m *Logger) DebugFn(fn logapi.MessageFunc) {
+	_m.Called(fn)
+}
+
+// Debugf provides a mock function with given fields: format, args
+func (_m *Logger) Debugf(format string, args ...interface{}) {
+	var _ca []interface{}
+	_ca = append(_ca, format)
+	_ca = append(_ca, args...)
+	_m.Called(_ca...)
+}
+
+// Enabled provides a mock function with given fields: level
+func (_m *Logger) Enabled(level logapi.Level) bool {
+	ret := _m.Called(level)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Enabled")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(logapi.Level) bool); ok {
+		r0 = rf(level)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // Error provides a mock function with given fields: message, args
 func (_m *Logger) Error(message string, args ...interface{}) {
 	var _ca []interface{}
@@ -25,6 +81,39 @@ func (_m *Logger) Error(message string, args ...interface{}) {
 	_m.Called(_ca...)
 }
 
+// ErrorF provides a mock function with given fields: message, fields
+func (_m *Logger) ErrorF(message string, fields ...zapcore.Field) {
+	_va := make([]interface{}, len(fields))
+	for _i := range fields {
+		_va[_i] = fields[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, message)
+	_ca = append(_ca, _va...)
+	_m.Called(_ca...)
+}
+
+// ErrorFn provides a mock function with given fields: fn
+func (_m *Logger) ErrorFn(fn logapi.MessageFunc) {
+	_m.Called(fn)
+}
+
+// Errorf provides a mock function with given fields: format, args
+func (_m *Logger) Errorf(format string, args ...interface{}) {
+	var _ca []interface{}
+	_ca = append(_ca, format)
+	_ca = append(_ca, args...)
+	_m.Called(_ca...)
+}
+
+// Fatal provides a mock function with given fields: message, args
+func (_m *Logger) Fatal(message string, args ...interface{}) {
+	var _ca []interface{}
+	_ca = append(_ca, message)
+	_ca = append(_ca, args...)
+	_m.Called(_ca...)
+}
+
 // Info provides a mock function with given fields: message, args
 func (_m *Logger) Info(message string, args ...interface{}) {
 	var _ca []interface{}
@@ -33,6 +122,59 @@ func (_m *Logger) Info(message string, args ...interface{}) {
 	_m.Called(_ca...)
 }
 
+// InfoF provides a mock function with given fields: message, fields
+func (_m *Logger) InfoF(message string, fields ...zapcore.Field) {
+	_va := make([]interface{}, len(fields))
+	for _i := range fields {
+		_va[_i] = fields[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, message)
+	_ca = append(_ca, _va...)
+	_m.Called(_ca...)
+}
+
+// InfoFn provides a mock function with given fields: fn
+func (_m *Logger) InfoFn(fn logapi.MessageFunc) {
+	_m.Called(fn)
+}
+
+// Infof provides a mock function with given fields: format, args
+func (_m *Logger) Infof(format string, args ...interface{}) {
+	var _ca []interface{}
+	_ca = append(_ca, format)
+	_ca = append(_ca, args...)
+	_m.Called(_ca...)
+}
+
+// Named provides a mock function with given fields: name
+func (_m *Logger) Named(name string) logapi.Logger {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Named")
+	}
+
+	var r0 logapi.Logger
+	if rf, ok := ret.Get(0).(func(string) logapi.Logger); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(logapi.Logger)
+		}
+	}
+
+	return r0
+}
+
+// Panic provides a mock function with given fields: message, args
+func (_m *Logger) Panic(message string, args ...interface{}) {
+	var _ca []interface{}
+	_ca = append(_ca, message)
+	_ca = append(_ca, args...)
+	_m.Called(_ca...)
+}
+
 // Shutdown provides a mock function with no fields
 func (_m *Logger) Shutdown() error {
 	ret := _m.Called()
@@ -56,6 +198,39 @@ func (_m *Logger) Sync() {
 	_m.Called()
 }
 
+// Trace provides a mock function with given fields: message, args
+func (_m *Logger) Trace(message string, args ...interface{}) {
+	var _ca []interface{}
+	_ca = append(_ca, message)
+	_ca = append(_ca, args...)
+	_m.Called(_ca...)
+}
+
+// TraceF provides a mock function with given fields: message, fields
+func (_m *Logger) TraceF(message string, fields ...zapcore.Field) {
+	_va := make([]interface{}, len(fields))
+	for _i := range fields {
+		_va[_i] = fields[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, message)
+	_ca = append(_ca, _va...)
+	_m.Called(_ca...)
+}
+
+// TraceFn provides a mock function with given fields: fn
+func (_m *Logger) TraceFn(fn logapi.MessageFunc) {
+	_m.Called(fn)
+}
+
+// Tracef provides a mock function with given fields: format, args
+func (_m *Logger) Tracef(format string, args ...interface{}) {
+	var _ca []interface{}
+	_ca = append(_ca, format)
+	_ca = append(_ca, args...)
+	_m.Called(_ca...)
+}
+
 // Warn provides a mock function with given fields: message, args
 func (_m *Logger) Warn(message string, args ...interface{}) {
 	var _ca []interface{}
@@ -64,6 +239,53 @@ func (_m *Logger) Warn(message string, args ...interface{}) {
 	_m.Called(_ca...)
 }
 
+// WarnF provides a mock function with given fields: message, fields
+func (_m *Logger) WarnF(message string, fields ...zapcore.Field) {
+	_va := make([]interface{}, len(fields))
+	for _i := range fields {
+		_va[_i] = fields[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, message)
+	_ca = append(_ca, _va...)
+	_m.Called(_ca...)
+}
+
+// WarnFn provides a mock function with given fields: fn
+func (_m *Logger) WarnFn(fn logapi.MessageFunc) {
+	_m.Called(fn)
+}
+
+// Warnf provides a mock function with given fields: format, args
+func (_m *Logger) Warnf(format string, args ...interface{}) {
+	var _ca []interface{}
+	_ca = append(_ca, format)
+	_ca = append(_ca, args...)
+	_m.Called(_ca...)
+}
+
+// With provides a mock function with given fields: args
+func (_m *Logger) With(args ...interface{}) logapi.Logger {
+	var _ca []interface{}
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for With")
+	}
+
+	var r0 logapi.Logger
+	if rf, ok := ret.Get(0).(func(...interface{}) logapi.Logger); ok {
+		r0 = rf(args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(logapi.Logger)
+		}
+	}
+
+	return r0
+}
+
 // NewLogger creates a new instance of Logger. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewLogger(t interface {
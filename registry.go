@@ -0,0 +1,85 @@
+package common_logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Factory creates a logger of a registered backend from the given resolved and validated configuration
+type Factory func(ConfigV2) (Logger, error)
+
+type backend struct {
+	name    string
+	factory Factory
+}
+
+var (
+	registryMu sync.RWMutex
+	// backends is indexed by LoggerType; the built-in backends are registered in the order of their constants
+	backends = []backend{
+		Zap:  {name: "Zap", factory: startLogger},
+		Slog: {name: "Slog", factory: startSlogLogger},
+	}
+)
+
+// Register makes a logging backend available under the given name and returns its LoggerType.
+// Names are case-insensitive. Like database/sql.Register, it panics if the name is already registered or the factory is nil.
+func Register(name string, factory Factory) LoggerType {
+	if factory == nil {
+		panic("common_logger: Register factory is nil")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := lookupName(name); ok {
+		panic("common_logger: Register called twice for backend " + name)
+	}
+
+	backends = append(backends, backend{name: name, factory: factory})
+	return LoggerType(len(backends) - 1)
+}
+
+// ParseLoggerType returns the LoggerType registered under the given name (case-insensitive)
+func ParseLoggerType(name string) (LoggerType, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	if l, ok := lookupName(name); ok {
+		return l, nil
+	}
+	return -1, fmt.Errorf("unknown logger type %q, available: %s", name, availableBackends())
+}
+
+// lookupName must be called with registryMu held
+func lookupName(name string) (LoggerType, bool) {
+	for i, b := range backends {
+		if strings.EqualFold(b.name, name) {
+			return LoggerType(i), true
+		}
+	}
+	return -1, false
+}
+
+// lookupBackend returns the registered backend of the given LoggerType
+func lookupBackend(l LoggerType) (backend, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	if l < 0 || int(l) >= len(backends) {
+		return backend{}, fmt.Errorf("unknown logger type %d, available: %s", l, availableBackends())
+	}
+	return backends[l], nil
+}
+
+// availableBackends must be called with registryMu held
+func availableBackends() string {
+	names := make([]string, 0, len(backends))
+	for _, b := range backends {
+		names = append(names, b.name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
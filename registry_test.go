@@ -0,0 +1,75 @@
+package common_logger
+
+import (
+	"errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vlbarou/logger/default_logger"
+	"github.com/vlbarou/logger/mocks"
+)
+
+func (suite *LoggerTestSuite) TestRegisterBackend() {
+
+	// arrange
+	custom := new(mocks.Logger)
+	var received ConfigV2
+	memory := Register("Memory", func(config ConfigV2) (Logger, error) {
+		received = config
+		return custom, nil
+	})
+
+	// act
+	l, err := GetLoggerByName("memory", Config{LogFile: "memory.log"})
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Same(suite.T(), custom, l)
+	assert.Equal(suite.T(), "memory.log", received.LogFile)
+	assert.Equal(suite.T(), "Memory", memory.String())
+	assert.Panics(suite.T(), func() { Register("MEMORY", func(ConfigV2) (Logger, error) { return custom, nil }) })
+}
+
+func (suite *LoggerTestSuite) TestUnknownBackend() {
+
+	// act
+	l, err := GetLoggerByName("nope")
+
+	// assert
+	_, ok := l.(*default_logger.DefaultLogger)
+	assert.True(suite.T(), ok)
+	assert.ErrorContains(suite.T(), err, `unknown logger type "nope", available: `)
+	assert.ErrorContains(suite.T(), err, "Slog, Zap")
+	assert.Equal(suite.T(), "Unknown", LoggerType(100).String())
+}
+
+func (suite *LoggerTestSuite) TestFailingBackend() {
+
+	// arrange
+	failing := Register("Failing", func(ConfigV2) (Logger, error) {
+		return nil, errors.New("boom")
+	})
+
+	// act
+	l, err := GetLogger(failing)
+
+	// assert
+	_, ok := l.(*default_logger.DefaultLogger)
+	assert.True(suite.T(), ok)
+	assert.ErrorContains(suite.T(), err, "failed to create Failing logger: boom")
+}
+
+func (suite *LoggerTestSuite) TestLoggerTypeText() {
+
+	// arrange
+	var l LoggerType
+
+	// act
+	err := l.UnmarshalText([]byte("slog"))
+	text, _ := l.MarshalText()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), Slog, l)
+	assert.Equal(suite.T(), "Slog", string(text))
+	assert.NotNil(suite.T(), l.UnmarshalText([]byte("nope")))
+}
@@ -0,0 +1,122 @@
+package common_logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/vlbarou/logger/zapLogger"
+)
+
+// Reconfigurable is implemented by the loggers of custom backends that can be reconfigured at runtime
+type Reconfigurable interface {
+	Reconfigure(config ConfigV2) error
+}
+
+var (
+	globalConfigMu sync.Mutex
+	globalConfig   ConfigV2 // configuration of the global logger, set by GetLogger and Reconfigure
+)
+
+// setGlobalConfig records the configuration the global logger is created with
+func setGlobalConfig(config []Configuration) {
+	cfg, err := resolveConfig(config)
+	if err != nil {
+		cfg = DefaultConfigV2() // the default logger is initialized
+	}
+
+	globalConfigMu.Lock()
+	defer globalConfigMu.Unlock()
+	globalConfig = cfg
+}
+
+// Reconfigure replaces at runtime the configuration of the global logger (outputs, log file, rotation, level, asynchronous
+// writes, sampling, collapsing of the duplicates and redaction), while it keeps being used by other goroutines.
+// The configuration is validated first; if it is invalid, nothing changes.
+func Reconfigure(config Configuration) error {
+	globalConfigMu.Lock()
+	defer globalConfigMu.Unlock()
+	return reconfigure(config)
+}
+
+// reconfigure must be called with globalConfigMu held
+func reconfigure(config Configuration) error {
+	cfg, err := config.Resolve()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err = cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	switch l := instance().(type) {
+	case *zapLogger.LoggerImpl:
+		err = l.Reconfigure(zapSettings(cfg))
+	case Reconfigurable:
+		err = l.Reconfigure(cfg)
+	default:
+		return errors.New("the logger does not support reconfiguration")
+	}
+	if err != nil {
+		return err
+	}
+	globalConfig = cfg
+	return nil
+}
+
+// WatchConfig polls the configuration file every interval and, when it changes, reloads it with LoadConfig
+// (so the environment variables with the given prefix still take precedence) and reconfigures the global logger.
+// Only the fields that can be loaded are replaced; the others (e.g. the redaction or the sampling) are kept.
+// Reload failures are logged, and the logger keeps its configuration. Call the returned function to stop watching;
+// it returns once a reload in progress is over, so that the logger is no longer reconfigured, e.g. before Shutdown.
+func WatchConfig(path string, envPrefix string, interval time.Duration) (stop func()) {
+	done, stopped := make(chan struct{}), make(chan struct{})
+	last, _ := os.Stat(path)
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			info, err := os.Stat(path)
+			if err != nil || !changed(last, info) {
+				continue
+			}
+			last = info
+
+			config, err := LoadConfig(path, envPrefix)
+			if err == nil {
+				err = reload(config)
+			}
+			if err != nil {
+				Error("Failed to reload logger configuration", "path", path, "error", err)
+			}
+		}
+	}()
+
+	return sync.OnceFunc(func() {
+		close(done)
+		<-stopped
+	})
+}
+
+// reload reconfigures the global logger with the loaded fields, merged onto its current configuration
+func reload(config LoadedConfig) error {
+	globalConfigMu.Lock()
+	defer globalConfigMu.Unlock()
+	return reconfigure(config.mergeInto(globalConfig))
+}
+
+// changed reports whether the file has been modified since the previous stat
+func changed(previous os.FileInfo, current os.FileInfo) bool {
+	return previous == nil || !previous.ModTime().Equal(current.ModTime()) || previous.Size() != current.Size()
+}
@@ -0,0 +1,85 @@
+package common_logger
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vlbarou/logger/zapLogger"
+	"go.uber.org/zap/zapcore"
+)
+
+func (suite *LoggerTestSuite) TestWatchConfig() {
+
+	var err error
+
+	// arrange
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+	path := filepath.Join(suite.tempDir, "logger.yaml")
+	err = os.WriteFile(path, []byte("logFile: "+suite.tempLogFile.Name()+"\noutputs: [file]\n"), 0o644)
+
+	config, _ := ConfigFromFile(path)
+	_, err = GetLogger(Zap, config)
+	l, _ := instance().(*zapLogger.LoggerImpl)
+
+	// act
+	stop := WatchConfig(path, "", 10*time.Millisecond)
+	_ = os.WriteFile(path, []byte("logFile: "+suite.tempLogFile.Name()+"\noutputs: [file]\nlevel: debug\n"), 0o644)
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Eventually(suite.T(), func() bool {
+		return l.Level("") == zapcore.DebugLevel
+	}, 2*time.Second, 10*time.Millisecond)
+	stop()
+	assert.Nil(suite.T(), Shutdown())
+}
+
+func (suite *LoggerTestSuite) TestReconfigureRejectsInvalidConfig() {
+
+	var err error
+
+	// arrange
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+	_, err = GetLogger(Zap, ConfigV2{LogFile: suite.tempLogFile.Name(), Outputs: []string{OutputFile}})
+
+	// act
+	reconfigureErr := Reconfigure(ConfigV2{Outputs: []string{"syslog"}})
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.ErrorContains(suite.T(), reconfigureErr, `unknown output "syslog"`)
+	assert.Nil(suite.T(), Shutdown())
+}
+
+func (suite *LoggerTestSuite) TestWatchConfigKeepsTheSettingsThatCannotBeLoaded() {
+
+	var err error
+
+	// arrange
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+	path := filepath.Join(suite.tempDir, "logger.yaml")
+	err = os.WriteFile(path, []byte("logFile: "+suite.tempLogFile.Name()+"\noutputs: [file]\n"), 0o644)
+
+	config, _ := ConfigFromFile(path)
+	config.Redaction = RedactionConfig{Keys: []string{"password"}}
+	_, err = GetLogger(Zap, config)
+	l, _ := instance().(*zapLogger.LoggerImpl)
+
+	// act
+	stop := WatchConfig(path, "", 10*time.Millisecond)
+	_ = os.WriteFile(path, []byte("logFile: "+suite.tempLogFile.Name()+"\noutputs: [file]\nlevel: debug\n"), 0o644)
+	reloaded := assert.Eventually(suite.T(), func() bool {
+		return l.Level("") == zapcore.DebugLevel
+	}, 2*time.Second, 10*time.Millisecond)
+	Info("login", "password", "secret")
+	stop()
+	shutdownErr := Shutdown()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), reloaded)
+	assert.Nil(suite.T(), shutdownErr)
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "login"), `"password":"***"`)
+}
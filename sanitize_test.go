@@ -0,0 +1,113 @@
+package common_logger
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vlbarou/logger/default_logger"
+	"github.com/vlbarou/logger/logapi"
+)
+
+// hostileInputs returns the corpus of the log injection tests
+func hostileInputs(tb testing.TB) []string {
+	file, err := os.Open("testdata/hostile.txt")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer file.Close()
+
+	var inputs []string
+	for scanner := bufio.NewScanner(file); scanner.Scan(); {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		input, err := strconv.Unquote(line)
+		if err != nil {
+			tb.Fatalf("invalid corpus line %s: %v", line, err)
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs
+}
+
+// assertSingleSafeLine checks that the output is one line, without control characters
+func assertSingleSafeLine(tb testing.TB, output string, input string) {
+	line, found := strings.CutSuffix(output, "\n")
+	if !found || strings.IndexFunc(line, func(r rune) bool {
+		return unicode.IsControl(r) || r == '\u2028' || r == '\u2029' || r == '\u202e'
+	}) >= 0 {
+		tb.Errorf("unsafe output %q for input %q", output, input)
+	}
+}
+
+func (suite *LoggerTestSuite) TestSanitizeText() {
+
+	for _, test := range []struct {
+		input    string
+		expected string
+	}{
+		{"plain text, ünïcode and emoji 🎉", "plain text, ünïcode and emoji 🎉"},
+		{"a\nb\r\tc", `a\nb\r\tc`},
+		{"\x1b[31mred", `\x1b[31mred`},
+		{"nul\x00 del\x7f c1\u0085", `nul\x00 del\x7f c1\u0085`},
+		{"ls\u2028bidi\u202e", `ls\u2028bidi\u202e`},
+		{"bad\xffutf8", `bad\xffutf8`},
+	} {
+		assert.Equal(suite.T(), test.expected, logapi.SanitizeText(test.input), test.input)
+	}
+}
+
+func (suite *LoggerTestSuite) TestDefaultLoggerHostileInputs() {
+
+	for _, input := range hostileInputs(suite.T()) {
+		var buf bytes.Buffer
+		l := default_logger.NewWithOutput(&buf)
+
+		// act
+		l.Named(input).With(input, input).Error(input, "value", input, "error", input)
+
+		// assert
+		assertSingleSafeLine(suite.T(), buf.String(), input)
+	}
+}
+
+func (suite *LoggerTestSuite) TestDefaultLoggerQuotesValues() {
+
+	// arrange
+	var buf bytes.Buffer
+	l := default_logger.NewWithOutput(&buf)
+
+	// act
+	l.Info("login", "user", "bob level=ERROR msg=forged", "note", `say "hi"`, "a=b", 1, "plain", "value")
+
+	// assert
+	assert.Contains(suite.T(), buf.String(), `msg="login" user="bob level=ERROR msg=forged" note="say \"hi\"" "a=b"=1 plain=value`+"\n")
+}
+
+func FuzzDefaultLoggerSanitization(f *testing.F) {
+	for _, input := range hostileInputs(f) {
+		f.Add(input)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		var buf bytes.Buffer
+		l := default_logger.NewWithOutput(&buf)
+
+		l.Named(input).Info(input, input, input)
+		l.Warnf("%s", input)
+
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+			assertSingleSafeLine(t, strings.TrimSuffix(line, "\n")+"\n", input)
+		}
+		if lines := strings.Count(buf.String(), "\n"); lines != 2 {
+			t.Errorf("%d lines written for input %q", lines, input)
+		}
+	})
+}
@@ -0,0 +1,319 @@
+package slogLogger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/vlbarou/logger/logapi"
+)
+
+// The slog levels of the severities that slog does not define
+const (
+	LevelTrace  = slog.LevelDebug - 4
+	LevelDPanic = slog.LevelError + 4
+	LevelPanic  = slog.LevelError + 8
+	LevelFatal  = slog.LevelError + 12
+)
+
+// LoggerImpl implements the Logger interface on top of any slog.Handler
+type LoggerImpl struct {
+	handler     slog.Handler
+	name        string         // name of the logger, as set by `Named`, e.g. "db.pool"
+	callerSkip  int            // frames of the wrappers of the logger, as set by `WithCallerSkip`
+	exit        func(int)      // called by Fatal (os.Exit by default)
+	development bool           // DPanic panics in development mode
+	malformed   *atomic.Uint64 // malformed key-value arguments, shared with the children
+}
+
+// New returns a logger that writes to the given handler, or to the handler of slog.Default() if it is nil
+func New(handler slog.Handler) *LoggerImpl {
+	if handler == nil {
+		handler = slog.Default().Handler()
+	}
+
+	return &LoggerImpl{
+		handler:   handler,
+		exit:      os.Exit,
+		malformed: new(atomic.Uint64),
+	}
+}
+
+// WithExitFunc sets the function called by Fatal (os.Exit by default)
+func (logger *LoggerImpl) WithExitFunc(exit func(int)) *LoggerImpl {
+	logger.exit = exit
+	return logger
+}
+
+// WithDevelopment enables the development mode, in which DPanic panics
+func (logger *LoggerImpl) WithDevelopment(d bool) *LoggerImpl {
+	logger.development = d
+	return logger
+}
+
+func (logger *LoggerImpl) Trace(message string, args ...any) {
+	logger.log(LevelTrace, message, args...)
+}
+
+func (logger *LoggerImpl) Info(message string, args ...any) {
+	logger.log(slog.LevelInfo, message, args...)
+}
+
+func (logger *LoggerImpl) Debug(message string, args ...any) {
+	logger.log(slog.LevelDebug, message, args...)
+}
+
+func (logger *LoggerImpl) Error(message string, args ...any) {
+	logger.log(slog.LevelError, message, args...)
+}
+
+func (logger *LoggerImpl) Warn(message string, args ...any) {
+	logger.log(slog.LevelWarn, message, args...)
+}
+
+func (logger *LoggerImpl) Tracef(format string, args ...any) {
+	logger.logf(LevelTrace, format, args)
+}
+
+func (logger *LoggerImpl) Debugf(format string, args ...any) {
+	logger.logf(slog.LevelDebug, format, args)
+}
+
+func (logger *LoggerImpl) Infof(format string, args ...any) {
+	logger.logf(slog.LevelInfo, format, args)
+}
+
+func (logger *LoggerImpl) Warnf(format string, args ...any) {
+	logger.logf(slog.LevelWarn, format, args)
+}
+
+func (logger *LoggerImpl) Errorf(format string, args ...any) {
+	logger.logf(slog.LevelError, format, args)
+}
+
+func (logger *LoggerImpl) TraceFn(fn logapi.MessageFunc) {
+	if logger.handler.Enabled(context.Background(), LevelTrace) {
+		message, args := fn()
+		logger.log(LevelTrace, message, args...)
+	}
+}
+
+func (logger *LoggerImpl) DebugFn(fn logapi.MessageFunc) {
+	if logger.handler.Enabled(context.Background(), slog.LevelDebug) {
+		message, args := fn()
+		logger.log(slog.LevelDebug, message, args...)
+	}
+}
+
+func (logger *LoggerImpl) InfoFn(fn logapi.MessageFunc) {
+	if logger.handler.Enabled(context.Background(), slog.LevelInfo) {
+		message, args := fn()
+		logger.log(slog.LevelInfo, message, args...)
+	}
+}
+
+func (logger *LoggerImpl) WarnFn(fn logapi.MessageFunc) {
+	if logger.handler.Enabled(context.Background(), slog.LevelWarn) {
+		message, args := fn()
+		logger.log(slog.LevelWarn, message, args...)
+	}
+}
+
+func (logger *LoggerImpl) ErrorFn(fn logapi.MessageFunc) {
+	if logger.handler.Enabled(context.Background(), slog.LevelError) {
+		message, args := fn()
+		logger.log(slog.LevelError, message, args...)
+	}
+}
+
+func (logger *LoggerImpl) TraceF(message string, fields ...logapi.Field) {
+	logger.logF(LevelTrace, message, fields)
+}
+
+func (logger *LoggerImpl) DebugF(message string, fields ...logapi.Field) {
+	logger.logF(slog.LevelDebug, message, fields)
+}
+
+func (logger *LoggerImpl) InfoF(message string, fields ...logapi.Field) {
+	logger.logF(slog.LevelInfo, message, fields)
+}
+
+func (logger *LoggerImpl) WarnF(message string, fields ...logapi.Field) {
+	logger.logF(slog.LevelWarn, message, fields)
+}
+
+func (logger *LoggerImpl) ErrorF(message string, fields ...logapi.Field) {
+	logger.logF(slog.LevelError, message, fields)
+}
+
+// MalformedArgs returns the number of malformed key-value arguments given to the logger and its children (see logapi.NormalizeArgs)
+func (logger *LoggerImpl) MalformedArgs() uint64 {
+	return logger.malformed.Load()
+}
+
+// Enabled reports whether the handler handles the records of the given level
+func (logger *LoggerImpl) Enabled(level logapi.Level) bool {
+	return logger.handler.Enabled(context.Background(), toSlogLevel(level))
+}
+
+// DPanic logs at LevelDPanic, and panics in development mode
+func (logger *LoggerImpl) DPanic(message string, args ...any) {
+	logger.log(LevelDPanic, message, args...)
+	if logger.development {
+		panic(message)
+	}
+}
+
+// Panic logs at LevelPanic, then panics
+func (logger *LoggerImpl) Panic(message string, args ...any) {
+	logger.log(LevelPanic, message, args...)
+	panic(message)
+}
+
+// Fatal logs at LevelFatal, then calls the exit function (see WithExitFunc)
+func (logger *LoggerImpl) Fatal(message string, args ...any) {
+	logger.log(LevelFatal, message, args...)
+	logger.exit(1)
+}
+
+// With returns a child logger that adds the given key-value pairs to every record
+func (logger *LoggerImpl) With(args ...any) logapi.Logger {
+	return &LoggerImpl{
+		handler:     slog.New(logger.handler).With(logger.normalize(args)...).Handler(),
+		name:        logger.name,
+		callerSkip:  logger.callerSkip,
+		exit:        logger.exit,
+		development: logger.development,
+		malformed:   logger.malformed,
+	}
+}
+
+// Named returns a child logger whose name is the dot-separated join of the parent name and the given name.
+// The name is logged under the "logger" key, as the zap logger does.
+func (logger *LoggerImpl) Named(name string) logapi.Logger {
+	if logger.name != "" {
+		name = logger.name + "." + name
+	}
+	return &LoggerImpl{
+		handler:     logger.handler,
+		name:        name,
+		callerSkip:  logger.callerSkip,
+		exit:        logger.exit,
+		development: logger.development,
+		malformed:   logger.malformed,
+	}
+}
+
+// WithCallerSkip returns a child logger that skips n more stack frames to report the caller,
+// for callers that wrap the logger in their own functions
+func (logger *LoggerImpl) WithCallerSkip(n int) logapi.Logger {
+	return &LoggerImpl{
+		handler:     logger.handler,
+		name:        logger.name,
+		callerSkip:  logger.callerSkip + n,
+		exit:        logger.exit,
+		development: logger.development,
+		malformed:   logger.malformed,
+	}
+}
+
+// Shutdown is a no-op, since the handler is owned by the caller
+func (logger *LoggerImpl) Shutdown() error {
+	return nil
+}
+
+// Sync is a no-op, since slog handlers have no flush method
+func (logger *LoggerImpl) Sync() {
+}
+
+func (logger *LoggerImpl) log(level slog.Level, message string, args ...any) {
+	ctx := context.Background()
+	if !logger.handler.Enabled(ctx, level) {
+		return
+	}
+
+	// skip [runtime.Callers, log, Info] and the wrappers of the logger
+	var pcs [1]uintptr
+	runtime.Callers(3+logger.callerSkip, pcs[:])
+
+	args = logger.normalize(args)
+	rendered, template := logapi.RenderTemplate(message, args)
+	if template {
+		args = append(args[:len(args):len(args)], logapi.TemplateKey, message)
+	}
+	logger.handle(ctx, level, rendered, args, pcs[0])
+}
+
+// logf is the printf-style log; formatted messages are not templates
+func (logger *LoggerImpl) logf(level slog.Level, format string, args []any) {
+	ctx := context.Background()
+	if !logger.handler.Enabled(ctx, level) {
+		return
+	}
+
+	// skip [runtime.Callers, logf, Infof] and the wrappers of the logger
+	var pcs [1]uintptr
+	runtime.Callers(3+logger.callerSkip, pcs[:])
+
+	logger.handle(ctx, level, fmt.Sprintf(format, args...), nil, pcs[0])
+}
+
+// normalize returns the key-value pairs with the malformed ones fixed (see logapi.NormalizeArgs), counting them
+func (logger *LoggerImpl) normalize(args []any) []any {
+	normalized, malformed := logapi.NormalizeArgs(args)
+	if malformed > 0 {
+		logger.malformed.Add(uint64(malformed))
+		logapi.ReportMalformed(args, malformed)
+	}
+	return normalized
+}
+
+// logF is the log of the typed fields, converted to attributes
+func (logger *LoggerImpl) logF(level slog.Level, message string, fields []logapi.Field) {
+	ctx := context.Background()
+	if !logger.handler.Enabled(ctx, level) {
+		return
+	}
+
+	// skip [runtime.Callers, logF, InfoF] and the wrappers of the logger
+	var pcs [1]uintptr
+	runtime.Callers(3+logger.callerSkip, pcs[:])
+
+	logger.handle(ctx, level, message, logapi.FieldArgs(fields), pcs[0])
+}
+
+func (logger *LoggerImpl) handle(ctx context.Context, level slog.Level, message string, args []any, pc uintptr) {
+	record := slog.NewRecord(time.Now(), level, message, pc)
+	if logger.name != "" {
+		record.AddAttrs(slog.String("logger", logger.name))
+	}
+	record.Add(args...)
+
+	_ = logger.handler.Handle(ctx, record)
+}
+
+// toSlogLevel returns the slog level of the given severity
+func toSlogLevel(level logapi.Level) slog.Level {
+	switch level {
+	case logapi.TraceLevel:
+		return LevelTrace
+	case logapi.DebugLevel:
+		return slog.LevelDebug
+	case logapi.WarnLevel:
+		return slog.LevelWarn
+	case logapi.ErrorLevel:
+		return slog.LevelError
+	case logapi.DPanicLevel:
+		return LevelDPanic
+	case logapi.PanicLevel:
+		return LevelPanic
+	case logapi.FatalLevel:
+		return LevelFatal
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,72 @@
+package common_logger
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vlbarou/logger/default_logger"
+	"github.com/vlbarou/logger/logapi"
+)
+
+func (suite *LoggerTestSuite) TestRenderTemplate() {
+
+	for _, test := range []struct {
+		message  string
+		args     []any
+		expected string
+		template bool
+	}{
+		{"User {user} bought {count} items", []any{"user", "bob", "count", 3}, "User bob bought 3 items", true},
+		{"{user.id} failed: {err}", []any{"err", errors.New("timeout"), "user.id", 7}, "7 failed: timeout", true},
+		{"User {user} bought {count} items", []any{"user", "bob"}, "User bob bought {count} items", true},
+		{"{{user}} is {user}", []any{"user", "bob"}, "{user} is bob", true},
+		{"no placeholder", []any{"user", "bob"}, "no placeholder", false},
+		{"escaped {{user}}", []any{"user", "bob"}, "escaped {{user}}", false},
+		{"escaped {{user}} and {user}", []any{"user", "bob"}, "escaped {user} and bob", true},
+		{"closing }} only", nil, "closing }} only", false},
+		{`{"json": {{1}}}`, nil, `{"json": {{1}}}`, false},
+		{`payload {"a":{"b":1}}`, nil, `payload {"a":{"b":1}}`, false},
+		{"{} {1st} {user name} {user", []any{"user", "bob"}, "{} {1st} {user name} {user", false},
+	} {
+		rendered, template := logapi.RenderTemplate(test.message, test.args)
+		assert.Equal(suite.T(), test.expected, rendered, test.message)
+		assert.Equal(suite.T(), test.template, template, test.message)
+	}
+}
+
+func (suite *LoggerTestSuite) TestMessageTemplates() {
+
+	// arrange
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, nil)
+
+	_, err := GetLogger(Slog, ConfigV2{SlogHandler: handler})
+
+	// act
+	Info("User {user} bought {count} items", "user", "bob", "count", 3)
+	Infof("formatted {%s}", "user")
+
+	// assert
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(lines))
+	assert.Contains(suite.T(), lines[0], `"msg":"User bob bought 3 items","user":"bob","count":3,"message_template":"User {user} bought {count} items"`)
+	assert.Contains(suite.T(), lines[1], `"msg":"formatted {user}"}`)
+}
+
+func (suite *LoggerTestSuite) TestDefaultLoggerMessageTemplates() {
+
+	// arrange
+	var buf bytes.Buffer
+	logger := default_logger.NewWithOutput(&buf).With("request_id", 42)
+
+	// act
+	logger.Warn("Retrying {attempt}", "attempt", 2)
+
+	// assert
+	assert.Contains(suite.T(), buf.String(), `msg="Retrying 2" request_id=42 attempt=2 message_template="Retrying {attempt}"`)
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"sync"
 	"unsafe"
 )
@@ -55,10 +56,32 @@ func getLoggerFieldValue(logger any, param string) any {
 	return nil
 }
 
+// findLogLine returns the first line of the log file that contains the given message, or "" if there is none
+func findLogLine(logFile string, msg string) string {
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.Contains(line, `"msg":"`+msg+`"`) {
+			return line
+		}
+	}
+	return ""
+}
+
 // ResetLogger re-initializes singleton `GetLogger` method
 // The trick is to reset the internal state of `Once` struct
 func ResetLogger() {
 	once = sync.Once{}
-	loggerInstance = nil
+	preInit = &preInitBuffer{}
+	setInstance(preInit.logger())
 	initError = nil
+	initType = 0
+	initConfig = nil
+
+	globalConfigMu.Lock()
+	defer globalConfigMu.Unlock()
+	globalConfig = ConfigV2{}
 }
@@ -1,40 +1,78 @@
 package common_logger
 
 import (
-	"strconv"
-
+	"github.com/vlbarou/logger/slogLogger"
 	"github.com/vlbarou/logger/zapLogger"
+	"go.uber.org/zap/zapcore"
 )
 
-func startLogger(config []Config) Logger {
-	loggerInstance = zapLogger.New()
-	l, _ := loggerInstance.(*zapLogger.LoggerImpl)
-
-	if len(config) > 0 {
-		if config[0].LogFile != "" {
-			l.WithLogfile(config[0].LogFile)
-		}
-
-		if config[0].MaxSizeMB != "" {
-			l.WithMaxSizeMB(toInt(config[0].MaxSizeMB))
-		}
-
-		if config[0].MaxBackups != "" {
-			l.WithMaxBackups(toInt(config[0].MaxBackups))
-		}
+func startLogger(config ConfigV2) (Logger, error) {
+	l := zapLogger.New().
+		WithSettings(zapSettings(config)).
+		WithAdmin(config.Admin).
+		WithDevelopment(config.Development)
+	if config.ExitFunc != nil {
+		l.WithExitFunc(config.ExitFunc)
+	}
+	if config.AdminServer {
+		l.WithPort(config.AdminPort)
+	}
 
-		if config[0].MaxAge != "" {
-			l.WithMaxAge(toInt(config[0].MaxAge))
+	if err := l.Start(); err != nil {
+		_ = l.Shutdown()
+		return nil, err
+	}
+	if config.AdminMux != nil {
+		if err := l.MountAdmin(config.AdminMux); err != nil {
+			_ = l.Shutdown()
+			return nil, err
 		}
+	}
+	return l, nil
+}
 
-		l.WithLogRotation(config[0].LogRotation)
+func startSlogLogger(config ConfigV2) (Logger, error) {
+	l := slogLogger.New(config.SlogHandler).WithDevelopment(config.Development)
+	if config.ExitFunc != nil {
+		l.WithExitFunc(config.ExitFunc)
 	}
+	return l, nil
+}
 
-	l.Start()
-	return l
+// zapSettings converts the configuration to the settings of the zap logger
+func zapSettings(config ConfigV2) zapLogger.Settings {
+	return zapLogger.Settings{
+		LogFile:     config.LogFile,
+		LogRotation: config.LogRotation,
+		MaxSizeMB:   config.MaxSizeMB,
+		MaxBackups:  config.MaxBackups,
+		MaxAge:      config.maxAgeDays(),
+		Level:       zapcore.Level(config.Level), // the values of the levels match
+		Outputs:     config.Outputs,
+		Async: zapLogger.AsyncSettings{
+			QueueSize:     config.Async.QueueSize,
+			Policy:        config.Async.Policy,
+			MinLevel:      zapcore.Level(config.Async.MinLevel),
+			FlushInterval: config.Async.FlushInterval,
+		},
+		Sampling:    zapSampling(config.Sampling),
+		DedupWindow: config.DedupWindow,
+		Redaction:   config.Redaction,
+	}
 }
 
-func toInt(s string) int {
-	i, _ := strconv.Atoi(s)
-	return i
+// zapSampling converts the sampling configuration to the settings of the zap logger
+func zapSampling(config SamplingConfig) zapLogger.SamplingSettings {
+	settings := zapLogger.SamplingSettings{
+		Default:         config.Default,
+		Loggers:         config.Loggers,
+		SummaryInterval: config.SummaryInterval,
+	}
+	if len(config.Levels) > 0 {
+		settings.Levels = make(map[zapcore.Level]zapLogger.SamplingRule, len(config.Levels))
+		for level, rule := range config.Levels {
+			settings.Levels[zapcore.Level(level)] = rule
+		}
+	}
+	return settings
 }
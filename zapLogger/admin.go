@@ -0,0 +1,255 @@
+package zapLogger
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AdminSettings secure the log server. Without a token nor an HMAC key, the requests are not authenticated,
+// which is only safe on the loopback interface.
+type AdminSettings struct {
+	Host         string // interface the log server listens on, AdminHost (loopback) if empty; e.g. "0.0.0.0" for all interfaces
+	BearerToken  string // the requests must carry `Authorization: Bearer <token>`
+	HMACKey      []byte // the requests must be signed with this key, see SignAdminRequest
+	CertFile     string // PEM certificate of the server, which enables TLS
+	KeyFile      string // PEM key of the certificate
+	ClientCAFile string // PEM certificates of the CAs of the clients, which enables mutual TLS: the clients need a certificate signed by one of them
+}
+
+// authenticated reports whether the requests must carry a token or a signature
+func (s AdminSettings) authenticated() bool {
+	return s.BearerToken != "" || len(s.HMACKey) > 0
+}
+
+// host returns the interface to listen on
+func (s AdminSettings) host() string {
+	if s.Host == "" {
+		return AdminHost
+	}
+	return s.Host
+}
+
+// loopback reports whether the log server only accepts local connections
+func (s AdminSettings) loopback() bool {
+	host := s.host()
+	ip := net.ParseIP(host)
+	return host == "localhost" || (ip != nil && ip.IsLoopback())
+}
+
+// Validate reports the incomplete TLS settings, and a log server that would accept unauthenticated requests
+// from the network. Start only warns about the latter.
+func (s AdminSettings) Validate() error {
+	var errs []error
+	if (s.CertFile == "") != (s.KeyFile == "") {
+		errs = append(errs, errors.New("CertFile and KeyFile must be set together"))
+	}
+	if s.ClientCAFile != "" && s.CertFile == "" {
+		errs = append(errs, errors.New("mutual TLS requires a server certificate"))
+	}
+	if _, _, err := net.SplitHostPort(s.Host); err == nil {
+		errs = append(errs, fmt.Errorf("host %q must not include the port", s.Host))
+	}
+	if !s.loopback() && !s.authenticated() && s.ClientCAFile == "" {
+		errs = append(errs, fmt.Errorf("the log server would accept unauthenticated requests on %s", s.host()))
+	}
+	return errors.Join(errs...)
+}
+
+// tlsConfig loads the certificates. It returns nil if TLS is disabled.
+func (s AdminSettings) tlsConfig() (*tls.Config, error) {
+	if s.CertFile == "" && s.KeyFile == "" {
+		if s.ClientCAFile != "" {
+			return nil, errors.New("mutual TLS requires a server certificate")
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the certificate: %w", err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if s.ClientCAFile == "" {
+		return config, nil
+	}
+
+	pem, err := os.ReadFile(s.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the client CAs: %w", err)
+	}
+	config.ClientCAs = x509.NewCertPool()
+	if !config.ClientCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", s.ClientCAFile)
+	}
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	return config, nil
+}
+
+// WithAdmin sets the bind address, the authentication and the TLS settings of the log server
+func (logger *LoggerImpl) WithAdmin(admin AdminSettings) *LoggerImpl {
+	logger.admin = admin
+	return logger
+}
+
+// SignAdminRequest signs a request to the log server with the HMAC key of its AdminSettings: the headers
+// AdminTimestampHeader and AdminSignatureHeader are set to the current time and to the hex-encoded HMAC-SHA256 of
+// the method, the URI, the timestamp and the body, separated by new lines. The signature expires after AdminSignatureMaxAge.
+func SignAdminRequest(r *http.Request, key []byte) error {
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	r.Header.Set(AdminTimestampHeader, timestamp)
+	r.Header.Set(AdminSignatureHeader, hex.EncodeToString(adminSignature(key, r.Method, r.URL.RequestURI(), timestamp, body)))
+	return nil
+}
+
+func adminSignature(key []byte, method string, uri string, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n", method, uri, timestamp)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// readBody reads the body of a request and replaces it, so that it can be read again
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(r.Body)
+	_ = r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// maxAdminBody bounds the bodies of the requests to the log server, read to check their signature
+const maxAdminBody = 1 << 20
+
+type principalKey struct{}
+
+// principal returns who sent the request to the log server, as set by authorize, for the audit records
+func principal(r *http.Request) string {
+	p, _ := r.Context().Value(principalKey{}).(string)
+	return p
+}
+
+// authorize rejects the requests to the log server that don't carry the token nor a valid signature,
+// and the requests that a browser sends on behalf of another site (see crossSite).
+// The client certificates are checked by the TLS handshake.
+func (logger *LoggerImpl) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if crossSite(r) {
+			logger.audit(r, "Admin request denied", zap.String("reason", "cross-site request"))
+			writeError(w, http.StatusForbidden, "cross-site requests are not allowed")
+			return
+		}
+		p, err := logger.authenticate(w, r)
+		if err != nil {
+			logger.audit(r, "Admin request denied", zap.NamedError("reason", err))
+			w.Header().Set("WWW-Authenticate", `Bearer realm="log server"`)
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+	})
+}
+
+// crossSite reports whether a browser sent the request on behalf of another site, e.g. a web page posting a form
+// to the log server, which accepts the unauthenticated requests on the loopback interface by default.
+// The clients other than browsers don't send these headers.
+func crossSite(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "", "same-origin", "none":
+	default:
+		return true
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		return err != nil || u.Host != r.Host
+	}
+	return false
+}
+
+// authenticate returns who sent the request: the subject of the client certificate, and how the request was authenticated
+func (logger *LoggerImpl) authenticate(w http.ResponseWriter, r *http.Request) (string, error) {
+	admin := logger.root().admin
+	var names []string
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		names = append(names, "cert:"+r.TLS.PeerCertificates[0].Subject.CommonName)
+	}
+	if !admin.authenticated() {
+		return strings.Join(append(names, "anonymous"), " "), nil
+	}
+
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		token, found := strings.CutPrefix(auth, "Bearer ")
+		if !found || admin.BearerToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(admin.BearerToken)) != 1 {
+			return "", errors.New("invalid bearer token")
+		}
+		return strings.Join(append(names, "token"), " "), nil
+	}
+
+	if signature := r.Header.Get(AdminSignatureHeader); signature != "" && len(admin.HMACKey) > 0 {
+		if err := checkSignature(w, r, admin.HMACKey, signature); err != nil {
+			return "", err
+		}
+		return strings.Join(append(names, "hmac"), " "), nil
+	}
+	return "", errors.New("missing credentials")
+}
+
+// checkSignature checks the signature and the timestamp of a request, see SignAdminRequest
+func checkSignature(w http.ResponseWriter, r *http.Request, key []byte, signature string) error {
+	timestamp := r.Header.Get(AdminTimestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q", timestamp)
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > AdminSignatureMaxAge || age < -AdminSignatureMaxAge {
+		return errors.New("expired signature")
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAdminBody)
+	body, err := readBody(r)
+	if err != nil {
+		return fmt.Errorf("failed to read the body: %w", err)
+	}
+	decoded, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(decoded, adminSignature(key, r.Method, r.URL.RequestURI(), timestamp, body)) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// audit writes a record of a request to the log server, whatever the levels and the sampling
+func (logger *LoggerImpl) audit(r *http.Request, message string, fields ...zap.Field) {
+	fields = append([]zap.Field{
+		zap.String("remote_addr", r.RemoteAddr),
+		zap.String("principal", principal(r)),
+		zap.String("method", r.Method),
+		zap.String("uri", r.URL.RequestURI()),
+	}, fields...)
+	logger.root().auditLogger.Info(message, fields...)
+}
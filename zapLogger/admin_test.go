@@ -0,0 +1,342 @@
+package zapLogger
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+// certificate is a key pair generated for the tests, signed by its parent, or self-signed if there is none
+type certificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newCertificate(t *testing.T, name string, parent *certificate, usage x509.ExtKeyUsage) *certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &certificate{cert: cert, key: key, der: der}
+}
+
+// write writes the certificate and its key as PEM files in dir, and returns their paths
+func (c *certificate) write(t *testing.T, dir string, name string) (string, string) {
+	key, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: key}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func (c *certificate) tls() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+func TestAdminSettings(t *testing.T) {
+	assert.Equal(t, AdminHost, AdminSettings{}.host())
+	assert.True(t, AdminSettings{}.loopback())
+	assert.True(t, AdminSettings{Host: "::1"}.loopback())
+	assert.False(t, AdminSettings{Host: "0.0.0.0"}.loopback())
+
+	tlsConfig, err := AdminSettings{}.tlsConfig()
+	assert.Nil(t, tlsConfig)
+	assert.Nil(t, err)
+	_, err = AdminSettings{ClientCAFile: "ca.crt"}.tlsConfig()
+	assert.ErrorContains(t, err, "mutual TLS requires a server certificate")
+	_, err = AdminSettings{CertFile: "missing.crt", KeyFile: "missing.key"}.tlsConfig()
+	assert.ErrorContains(t, err, "failed to load the certificate")
+
+	assert.Nil(t, AdminSettings{}.Validate())
+	assert.Nil(t, AdminSettings{Host: "0.0.0.0", BearerToken: "s3cret"}.Validate())
+	err = AdminSettings{Host: "0.0.0.0:8081", CertFile: "server.crt"}.Validate()
+	assert.ErrorContains(t, err, "CertFile and KeyFile must be set together")
+	assert.ErrorContains(t, err, `host "0.0.0.0:8081" must not include the port`)
+	assert.ErrorContains(t, err, "the log server would accept unauthenticated requests on 0.0.0.0:8081")
+}
+
+func (suite *ZapLogTestSuite) TestAdminAuthentication() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+	key := []byte("hmac key")
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort("").
+		WithAdmin(AdminSettings{BearerToken: "s3cret", HMACKey: key})
+	assert.Nil(suite.T(), suite.logger.Start())
+	handler := suite.logger.authorize(http.HandlerFunc(suite.logger.logLevelHandler))
+	serve := func(r *http.Request) int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, r)
+		return recorder.Code
+	}
+	request := func(query string, header ...string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, LogServerURI+query, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+		return r
+	}
+
+	signed := request("?level=warn")
+	signErr := SignAdminRequest(signed, key)
+	tampered := request("?level=warn")
+	_ = SignAdminRequest(tampered, key)
+	tampered.URL.RawQuery = "level=debug"
+	expired := request("?level=warn")
+	_ = SignAdminRequest(expired, key)
+	expired.Header.Set(AdminTimestampHeader, strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10))
+
+	// act & assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), signErr)
+	assert.Equal(suite.T(), http.StatusUnauthorized, serve(request("?level=debug")))
+	assert.Equal(suite.T(), http.StatusUnauthorized, serve(request("?level=debug", "Authorization", "Bearer guess")))
+	assert.Equal(suite.T(), http.StatusUnauthorized, serve(tampered))
+	assert.Equal(suite.T(), http.StatusUnauthorized, serve(expired))
+	assert.Equal(suite.T(), zapcore.InfoLevel, suite.logger.Level(""))
+
+	assert.Equal(suite.T(), http.StatusOK, serve(request("?logger=db&level=debug", "Authorization", "Bearer s3cret")))
+	assert.Equal(suite.T(), zapcore.DebugLevel, suite.logger.Level("db"))
+	assert.Equal(suite.T(), http.StatusOK, serve(signed))
+	assert.Equal(suite.T(), zapcore.WarnLevel, suite.logger.Level(""))
+
+	// the audit records are written whatever the level
+	suite.logger.Sync()
+	denied := findLogLine(suite.tempLogFile.Name(), "Admin request denied")
+	assert.Contains(suite.T(), denied, `"logger":"audit"`)
+	assert.Contains(suite.T(), denied, `"remote_addr":"192.0.2.1:1234"`)
+	assert.Contains(suite.T(), denied, `"reason":"missing credentials"`)
+	changed := findLogLine(suite.tempLogFile.Name(), "Log level changed")
+	assert.Contains(suite.T(), changed, `"remote_addr":"192.0.2.1:1234","principal":"token","method":"POST","uri":"/loglevel?logger=db&level=debug"`)
+	assert.Contains(suite.T(), changed, `"target":"db","old_level":"info","new_level":"debug"`)
+}
+
+func (suite *ZapLogTestSuite) TestAdminRejectsCrossSiteRequests() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	// unauthenticated, on the loopback interface by default
+	suite.logger = New().WithLogfile(suite.tempLogFile.Name()).WithOutputs(OutputFile).WithPort("")
+	assert.Nil(suite.T(), suite.logger.Start())
+	handler := suite.logger.AdminHandler()
+	serve := func(method string, header ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, LogServerURI+"?level=debug", nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, r)
+		return recorder
+	}
+
+	// act
+	get := serve(http.MethodGet) // e.g. <img src="http://127.0.0.1:8081/loglevel?level=debug">
+	crossSite := serve(http.MethodPost, "Sec-Fetch-Site", "cross-site")
+	otherOrigin := serve(http.MethodPost, "Origin", "http://evil.example")
+	levelAfterAttempts := suite.logger.Level("")
+	sameOrigin := serve(http.MethodPost, "Origin", "http://example.com", "Sec-Fetch-Site", "same-origin")
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusMethodNotAllowed, get.Code)
+	assert.Equal(suite.T(), "POST, PUT", get.Header().Get("Allow"))
+	assert.Equal(suite.T(), http.StatusForbidden, crossSite.Code)
+	assert.Equal(suite.T(), http.StatusForbidden, otherOrigin.Code)
+	assert.Equal(suite.T(), zapcore.InfoLevel, levelAfterAttempts)
+	assert.Equal(suite.T(), http.StatusOK, sameOrigin.Code)
+	assert.Equal(suite.T(), zapcore.DebugLevel, suite.logger.Level(""))
+	suite.logger.Sync()
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "Admin request denied"), `"reason":"cross-site request"`)
+}
+
+func (suite *ZapLogTestSuite) TestAdminMutualTLS() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	ca := newCertificate(suite.T(), "test CA", nil, x509.ExtKeyUsageAny)
+	server := newCertificate(suite.T(), "log server", ca, x509.ExtKeyUsageServerAuth)
+	client := newCertificate(suite.T(), "operator", ca, x509.ExtKeyUsageClientAuth)
+	certFile, keyFile := server.write(suite.T(), suite.tempDir, "server")
+	caFile, _ := ca.write(suite.T(), suite.tempDir, "ca")
+
+	port := GetFreePort()
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort(port).
+		WithAdmin(AdminSettings{BearerToken: "s3cret", CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile})
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	newClient := func(certificates ...tls.Certificate) *http.Client {
+		return &http.Client{Timeout: time.Second, Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certificates},
+		}}
+	}
+	send := func(client *http.Client) (int, error) {
+		r, _ := http.NewRequest(http.MethodPost, "https://127.0.0.1:"+port+LogServerURI+"?level=error", nil)
+		r.Header.Set("Authorization", "Bearer s3cret")
+		response, err := client.Do(r)
+		if err != nil {
+			return 0, err
+		}
+		response.Body.Close()
+		return response.StatusCode, nil
+	}
+
+	// act
+	var status int
+	var sendErr error
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if status, sendErr = send(newClient(client.tls())); sendErr == nil {
+			break
+		}
+	}
+	_, anonymousErr := send(newClient())
+	suite.logger.Sync()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), sendErr)
+	assert.Equal(suite.T(), http.StatusOK, status)
+	assert.Equal(suite.T(), zapcore.ErrorLevel, suite.logger.Level(""))
+	assert.Error(suite.T(), anonymousErr)
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "Log level changed"), `"principal":"cert:operator token"`)
+}
+
+func (suite *ZapLogTestSuite) TestAdminServerOnFreePort() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort("0")
+	startErr := suite.logger.Start()
+	addr := suite.logger.AdminAddr()
+
+	// act
+	response, getErr := http.Post("http://"+addr.String()+LogServerURI+"?level=debug", "", nil)
+	if getErr == nil {
+		response.Body.Close()
+	}
+
+	// a second server can't listen on the same port
+	busy := New().WithOutputs(OutputFile).WithLogfile(suite.tempLogFile.Name()).WithPort(strconv.Itoa(addr.(*net.TCPAddr).Port))
+	busyErr := busy.Start()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), startErr)
+	assert.Nil(suite.T(), getErr)
+	assert.Equal(suite.T(), http.StatusOK, response.StatusCode)
+	assert.True(suite.T(), addr.(*net.TCPAddr).IP.IsLoopback())
+	assert.NotZero(suite.T(), addr.(*net.TCPAddr).Port)
+	assert.Equal(suite.T(), zapcore.DebugLevel, suite.logger.Level(""))
+	assert.ErrorContains(suite.T(), busyErr, "failed to start the log server")
+	assert.Nil(suite.T(), busy.AdminAddr())
+	assert.Nil(suite.T(), busy.Shutdown())
+}
+
+func (suite *ZapLogTestSuite) TestAdminServerDisabledByDefault() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().WithLogfile(suite.tempLogFile.Name()).WithOutputs(OutputFile)
+	startErr := suite.logger.Start()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), startErr)
+	assert.Nil(suite.T(), suite.logger.AdminAddr())
+}
+
+func (suite *ZapLogTestSuite) TestMountAdmin() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithAdmin(AdminSettings{BearerToken: "s3cret"})
+	startErr := suite.logger.Start()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	mountErr := suite.logger.MountAdmin(mux)
+	anonymousErr := New().MountAdmin(http.NewServeMux())
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// act
+	send := func(path string, token string) int {
+		r, _ := http.NewRequest(http.MethodPost, server.URL+path, nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		response, err := http.DefaultClient.Do(r)
+		if err != nil {
+			return 0
+		}
+		response.Body.Close()
+		return response.StatusCode
+	}
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), startErr)
+	assert.Nil(suite.T(), mountErr)
+	assert.ErrorContains(suite.T(), anonymousErr, "requires a BearerToken or an HMACKey")
+	assert.Equal(suite.T(), http.StatusNoContent, send("/health", ""))
+	assert.Equal(suite.T(), http.StatusUnauthorized, send(LogServerURI+"?level=warn", "guess"))
+	assert.Equal(suite.T(), http.StatusOK, send(LogServerURI+"?level=warn", "s3cret"))
+	assert.Equal(suite.T(), zapcore.WarnLevel, suite.logger.Level(""))
+}
@@ -0,0 +1,242 @@
+package zapLogger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/vlbarou/logger/logapi"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// The JSON API of the log server, under AdminAPIPrefix:
+//
+//	GET  /levels  the global level and the levels of the named loggers, see levelsDocument
+//	PUT  /levels  changes them, see levelsUpdate; the response is the new levels
+//	POST /rotate  rotates the log files, if the log rotation is enabled
+//	POST /sync    flushes the outputs
+//	GET  /config  the effective configuration, without the secrets
+//	GET  /stats   the number of records written by level, and the counters of the asynchronous writes
+//
+// The errors are answered as {"error": "..."}.
+func (logger *LoggerImpl) registerAPI(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+AdminAPIPrefix+"/levels", logger.getLevelsHandler)
+	mux.HandleFunc("PUT "+AdminAPIPrefix+"/levels", logger.putLevelsHandler)
+	mux.HandleFunc("POST "+AdminAPIPrefix+"/rotate", logger.rotateHandler)
+	mux.HandleFunc("POST "+AdminAPIPrefix+"/sync", logger.syncHandler)
+	mux.HandleFunc("GET "+AdminAPIPrefix+"/config", logger.configHandler)
+	mux.HandleFunc("GET "+AdminAPIPrefix+"/stats", logger.statsHandler)
+}
+
+// levelsDocument is the global level and the levels set for the named loggers, e.g. {"global": "info", "loggers": {"db": "debug"}}
+type levelsDocument struct {
+	Global  string            `json:"global"`
+	Loggers map[string]string `json:"loggers"`
+}
+
+// levelsUpdate changes the global level and the levels of the named loggers; the missing ones are kept,
+// and a null level resets the level of a named logger, which then inherits the level of its parent
+type levelsUpdate struct {
+	Global  *string            `json:"global"`
+	Loggers map[string]*string `json:"loggers"`
+}
+
+// currentLevels returns the global level and the levels set for the named loggers
+func (logger *LoggerImpl) currentLevels() levelsDocument {
+	root := logger.root()
+	document := levelsDocument{Global: levelName(root.Level("")), Loggers: make(map[string]string)}
+	for name, lvl := range root.levels.overridden() {
+		document.Loggers[name] = levelName(lvl)
+	}
+	return document
+}
+
+func (logger *LoggerImpl) getLevelsHandler(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, logger.currentLevels())
+}
+
+// putLevelsHandler applies all the changes, or none if one of them is invalid
+func (logger *LoggerImpl) putLevelsHandler(w http.ResponseWriter, r *http.Request) {
+	var update levelsUpdate
+	if err := readJSON(w, r, &update); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body: %v", err)
+		return
+	}
+
+	type change struct {
+		name  string
+		level *zapcore.Level // nil resets the level
+	}
+	var changes []change
+	if update.Global != nil {
+		lvl, err := parseLevel(*update.Global)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid global level %q", *update.Global)
+			return
+		}
+		changes = append(changes, change{level: &lvl})
+	}
+	for _, name := range slices.Sorted(maps.Keys(update.Loggers)) {
+		text := update.Loggers[name]
+		if name == "" {
+			writeError(w, http.StatusBadRequest, `the global level is set with "global"`)
+			return
+		}
+		if text == nil {
+			changes = append(changes, change{name: name})
+			continue
+		}
+		lvl, err := parseLevel(*text)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid level %q of logger %q", *text, name)
+			return
+		}
+		changes = append(changes, change{name: name, level: &lvl})
+	}
+
+	for _, c := range changes {
+		oldLevel := logger.Level(c.name)
+		if c.level == nil {
+			logger.ResetLevel(c.name)
+		} else {
+			logger.SetLevel(c.name, *c.level)
+		}
+		logger.audit(r, "Log level changed", zap.String("target", c.name), zap.String("old_level", levelName(oldLevel)),
+			zap.String("new_level", levelName(logger.Level(c.name))))
+	}
+	writeJSON(w, http.StatusOK, logger.currentLevels())
+}
+
+func (logger *LoggerImpl) rotateHandler(w http.ResponseWriter, r *http.Request) {
+	root := logger.root()
+	root.Sync() // the queued records belong to the rotated files
+
+	rotated, err := root.sinks.rotate()
+	switch {
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to rotate the log files: %v", err)
+	case !rotated:
+		writeError(w, http.StatusConflict, "log rotation is disabled")
+	default:
+		logger.audit(r, "Log files rotated")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (logger *LoggerImpl) syncHandler(w http.ResponseWriter, _ *http.Request) {
+	logger.Sync()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (logger *LoggerImpl) configHandler(w http.ResponseWriter, _ *http.Request) {
+	root := logger.root()
+	root.reconfigureMu.Lock()
+	settings := root.settings()
+	root.reconfigureMu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"log_file":     settings.LogFile,
+		"log_rotation": settings.LogRotation,
+		"max_size_mb":  settings.MaxSizeMB,
+		"max_backups":  settings.MaxBackups,
+		"max_age_days": settings.MaxAge,
+		"level":        levelName(root.Level("")),
+		"outputs":      settings.Outputs,
+		"async": map[string]any{
+			"queue_size":     settings.Async.QueueSize,
+			"policy":         settings.Async.Policy.String(),
+			"min_level":      levelName(settings.Async.MinLevel),
+			"flush_interval": settings.Async.FlushInterval.String(),
+		},
+		"sampling":     samplingConfig(settings.Sampling),
+		"dedup_window": settings.DedupWindow.String(),
+		"redaction":    redactionConfig(settings.Redaction),
+		"admin":        root.adminConfig(),
+	})
+}
+
+func samplingConfig(settings SamplingSettings) map[string]any {
+	rule := func(r SamplingRule) map[string]any {
+		return map[string]any{"first": r.First, "thereafter": r.Thereafter, "interval": r.Interval.String(), "sampler": r.Sampler.String()}
+	}
+	levels := make(map[string]any, len(settings.Levels))
+	for lvl, r := range settings.Levels {
+		levels[levelName(lvl)] = rule(r)
+	}
+	loggers := make(map[string]any, len(settings.Loggers))
+	for name, r := range settings.Loggers {
+		loggers[name] = rule(r)
+	}
+	return map[string]any{"default": rule(settings.Default), "levels": levels, "loggers": loggers, "summary_interval": settings.SummaryInterval.String()}
+}
+
+// redactionConfig describes the redaction; the hash key is masked
+func redactionConfig(config logapi.RedactionConfig) map[string]any {
+	patterns := make([]string, 0, len(config.Patterns))
+	for _, pattern := range config.Patterns {
+		patterns = append(patterns, pattern.Name)
+	}
+	return map[string]any{"keys": config.Keys, "patterns": patterns, "mode": config.Mode.String(), "hash_key": mask(len(config.HashKey) > 0)}
+}
+
+// adminConfig describes the log server; the token and the HMAC key are masked
+func (logger *LoggerImpl) adminConfig() map[string]any {
+	address := ""
+	if logger.adminAddr != nil {
+		address = logger.adminAddr.String()
+	}
+	return map[string]any{
+		"address":        address,
+		"host":           logger.admin.host(),
+		"port":           logger.logServerPort,
+		"bearer_token":   mask(logger.admin.BearerToken != ""),
+		"hmac_key":       mask(len(logger.admin.HMACKey) > 0),
+		"cert_file":      logger.admin.CertFile,
+		"key_file":       logger.admin.KeyFile,
+		"client_ca_file": logger.admin.ClientCAFile,
+	}
+}
+
+// mask returns logapi.RedactedMask for a secret that is set, and "" otherwise
+func mask(set bool) string {
+	if set {
+		return logapi.RedactedMask
+	}
+	return ""
+}
+
+func (logger *LoggerImpl) statsHandler(w http.ResponseWriter, _ *http.Request) {
+	async := logger.AsyncStats()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"levels":         logger.root().levelCounters.counts(),
+		"async":          map[string]uint64{"enqueued": async.Enqueued, "dropped": async.Dropped, "written": async.Written},
+		"malformed_args": logger.MalformedArgs(),
+	})
+}
+
+// readJSON decodes the body of a request, which must not have unknown fields
+func readJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdminBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return errors.New("unexpected data after the JSON document")
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...any) {
+	writeJSON(w, status, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
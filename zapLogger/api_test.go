@@ -0,0 +1,176 @@
+package zapLogger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vlbarou/logger/logapi"
+	"go.uber.org/zap/zapcore"
+)
+
+// apiResponse is a response of the JSON API of the log server
+type apiResponse struct {
+	status      int
+	contentType string
+	body        string
+}
+
+func (r apiResponse) decode() map[string]any {
+	var document map[string]any
+	_ = json.Unmarshal([]byte(r.body), &document)
+	return document
+}
+
+// callAPI sends a request to the JSON API of the log server, with the bearer token "s3cret"
+func callAPI(handler http.Handler, method string, path string, body string) apiResponse {
+	r := httptest.NewRequest(method, AdminAPIPrefix+path, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer s3cret")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, r)
+	content, _ := io.ReadAll(recorder.Result().Body)
+	return apiResponse{status: recorder.Code, contentType: recorder.Header().Get("Content-Type"), body: string(content)}
+}
+
+func (suite *ZapLogTestSuite) TestAPILevels() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithAdmin(AdminSettings{BearerToken: "s3cret"})
+	startErr := suite.logger.Start()
+	handler := suite.logger.AdminHandler()
+
+	// act
+	initial := callAPI(handler, http.MethodGet, "/levels", "")
+	updated := callAPI(handler, http.MethodPut, "/levels", `{"global": "warn", "loggers": {"db": "debug", "db.pool": "trace"}}`)
+	invalid := callAPI(handler, http.MethodPut, "/levels", `{"global": "error", "loggers": {"cache": "loud"}}`)
+	unknown := callAPI(handler, http.MethodPut, "/levels", `{"level": "error"}`)
+	reset := callAPI(handler, http.MethodPut, "/levels", `{"loggers": {"db": null}}`)
+	wrongMethod := callAPI(handler, http.MethodPost, "/levels", `{}`)
+	suite.logger.Sync()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), startErr)
+	assert.Equal(suite.T(), http.StatusOK, initial.status)
+	assert.JSONEq(suite.T(), `{"global": "info", "loggers": {}}`, initial.body)
+	assert.Equal(suite.T(), http.StatusOK, updated.status)
+	assert.JSONEq(suite.T(), `{"global": "warn", "loggers": {"db": "debug", "db.pool": "trace"}}`, updated.body)
+
+	// an invalid update changes nothing
+	assert.Equal(suite.T(), http.StatusBadRequest, invalid.status)
+	assert.Equal(suite.T(), `invalid level "loud" of logger "cache"`, invalid.decode()["error"])
+	assert.Equal(suite.T(), http.StatusBadRequest, unknown.status)
+	assert.Contains(suite.T(), unknown.decode()["error"], `unknown field "level"`)
+	assert.Equal(suite.T(), zapcore.WarnLevel, suite.logger.Level(""))
+
+	assert.Equal(suite.T(), http.StatusOK, reset.status)
+	assert.JSONEq(suite.T(), `{"global": "warn", "loggers": {"db.pool": "trace"}}`, reset.body)
+	assert.Equal(suite.T(), zapcore.WarnLevel, suite.logger.Level("db"))
+	assert.Equal(suite.T(), http.StatusMethodNotAllowed, wrongMethod.status)
+
+	audit := findLogLine(suite.tempLogFile.Name(), "Log level changed")
+	assert.Contains(suite.T(), audit, `"principal":"token","method":"PUT","uri":"/logger/v1/levels","target":"","old_level":"info","new_level":"warn"`)
+}
+
+func (suite *ZapLogTestSuite) TestAPIRotateAndSync() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithLogRotation(true).
+		WithAsync(AsyncSettings{QueueSize: 16, FlushInterval: time.Hour}).
+		WithAdmin(AdminSettings{BearerToken: "s3cret"})
+	startErr := suite.logger.Start()
+	handler := suite.logger.AdminHandler()
+
+	// act
+	suite.logger.Info("before sync")
+	synced := callAPI(handler, http.MethodPost, "/sync", "")
+	afterSync := findLogLine(suite.tempLogFile.Name(), "before sync")
+
+	suite.logger.Info("before rotation")
+	rotated := callAPI(handler, http.MethodPost, "/rotate", "")
+	afterRotation := findLogLine(suite.tempLogFile.Name(), "before rotation")
+
+	// wait for the compression of the rotated file
+	var files []os.DirEntry
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if files, _ = os.ReadDir(suite.tempDir); len(files) == 2 && strings.HasSuffix(files[0].Name(), ".gz") {
+			break
+		}
+	}
+
+	settings := suite.logger.settings()
+	settings.LogRotation = false
+	reconfigureErr := suite.logger.Reconfigure(settings)
+	disabled := callAPI(handler, http.MethodPost, "/rotate", "")
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), startErr)
+	assert.Nil(suite.T(), reconfigureErr)
+	assert.Equal(suite.T(), http.StatusNoContent, synced.status)
+	assert.NotEmpty(suite.T(), afterSync)
+	assert.Equal(suite.T(), http.StatusNoContent, rotated.status)
+	assert.Empty(suite.T(), afterRotation) // the record was written to the rotated file
+	assert.Len(suite.T(), files, 2)
+	assert.Equal(suite.T(), http.StatusConflict, disabled.status)
+	assert.Equal(suite.T(), "log rotation is disabled", disabled.decode()["error"])
+}
+
+func (suite *ZapLogTestSuite) TestAPIConfigAndStats() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithDedup(time.Minute).
+		WithRedaction(logapi.RedactionConfig{Keys: []string{"password"}, Mode: logapi.RedactHash, HashKey: []byte("hash key")}).
+		WithAdmin(AdminSettings{BearerToken: "s3cret", HMACKey: []byte("hmac key")})
+	startErr := suite.logger.Start()
+	handler := suite.logger.AdminHandler()
+
+	// act
+	suite.logger.Warn("first warning")
+	suite.logger.Warn("second warning")
+	suite.logger.Error("failure", "odd")
+	suite.logger.Debug("hidden")
+	stats := callAPI(handler, http.MethodGet, "/stats", "")
+	config := callAPI(handler, http.MethodGet, "/config", "")
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), startErr)
+	assert.Equal(suite.T(), http.StatusOK, stats.status)
+	assert.Equal(suite.T(), "application/json", stats.contentType)
+	counts := stats.decode()["levels"].(map[string]any)
+	assert.Equal(suite.T(), 2.0, counts["warn"])
+	assert.Equal(suite.T(), 1.0, counts["error"])
+	assert.Equal(suite.T(), 0.0, counts["debug"])
+	assert.Equal(suite.T(), 0.0, counts["trace"])
+	assert.Equal(suite.T(), 1.0, stats.decode()["malformed_args"])
+
+	assert.Equal(suite.T(), http.StatusOK, config.status)
+	document := config.decode()
+	assert.Equal(suite.T(), suite.tempLogFile.Name(), document["log_file"])
+	assert.Equal(suite.T(), "info", document["level"])
+	assert.Equal(suite.T(), "1m0s", document["dedup_window"])
+	assert.Equal(suite.T(), map[string]any{"keys": []any{"password"}, "patterns": []any{}, "mode": "hash", "hash_key": "***"}, document["redaction"])
+	assert.Equal(suite.T(), "***", document["admin"].(map[string]any)["bearer_token"])
+	assert.Equal(suite.T(), "***", document["admin"].(map[string]any)["hmac_key"])
+	assert.NotContains(suite.T(), config.body, "s3cret")
+	assert.NotContains(suite.T(), config.body, "hash key")
+	assert.NotContains(suite.T(), config.body, "hmac key")
+}
@@ -0,0 +1,340 @@
+package zapLogger
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// OverflowPolicy is what an asynchronous logger does with a record when its queue is full
+type OverflowPolicy int
+
+const (
+	OverflowBlock          OverflowPolicy = iota // the caller waits for room in the queue
+	OverflowDropNewest                           // the record is dropped
+	OverflowDropOldest                           // the oldest queued record is dropped to make room
+	OverflowDropBelowLevel                       // the record is dropped if its level is below AsyncSettings.MinLevel, otherwise the caller waits
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDropNewest:
+		return "drop_newest"
+	case OverflowDropOldest:
+		return "drop_oldest"
+	case OverflowDropBelowLevel:
+		return "drop_below_level"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+	}
+}
+
+// IsValid reports whether p is one of the defined policies
+func (p OverflowPolicy) IsValid() bool {
+	return p >= OverflowBlock && p <= OverflowDropBelowLevel
+}
+
+// AsyncSettings enable the asynchronous writes: the records are encoded by the caller, queued,
+// and written to the outputs by a single goroutine, so that a slow output doesn't stall the callers
+type AsyncSettings struct {
+	QueueSize     int            // number of queued records; 0 disables the asynchronous writes
+	Policy        OverflowPolicy // what to do with a record when the queue is full
+	MinLevel      zapcore.Level  // with OverflowDropBelowLevel, the records of this level and above are never dropped
+	FlushInterval time.Duration  // how often the outputs are flushed; 0 flushes them as soon as the queue is drained
+}
+
+// AsyncStats are the counters of the asynchronous writes of a logger, across its reconfigurations
+type AsyncStats struct {
+	Enqueued uint64 // records queued
+	Dropped  uint64 // records dropped, because the queue was full
+	Written  uint64 // records written to the outputs
+}
+
+type asyncCounters struct {
+	enqueued atomic.Uint64
+	dropped  atomic.Uint64
+	written  atomic.Uint64
+}
+
+// asyncOutput buffers the writes to an output; it is only written to by the goroutine of the pipeline
+type asyncOutput struct {
+	w   *bufio.Writer
+	out zapcore.WriteSyncer
+}
+
+type asyncRecord struct {
+	out   *asyncOutput
+	buf   *buffer.Buffer
+	level zapcore.Level
+}
+
+// asyncPipeline is the queue of the encoded records of all the outputs of a logger, and the goroutine that writes them.
+// It is closed along with the outputs, when they are replaced or the logger is shut down: the queued records are
+// written, and the next records are written synchronously.
+type asyncPipeline struct {
+	settings AsyncSettings
+	stats    *asyncCounters
+	outputs  []*asyncOutput
+
+	mu             sync.Mutex
+	cond           *sync.Cond // signals the changes of the queue, of the counters and of the flags
+	queue          []asyncRecord
+	head, count    int    // ring buffer
+	accepted, done uint64 // records accepted in the queue, and records written or dropped since
+	flushed        uint64 // value of done when the outputs were last flushed
+	flushRequested bool
+	closed         bool
+	waiting        int   // callers waiting for room in the queue
+	err            error // first write error, returned by Sync
+	stopped        chan struct{}
+}
+
+func newAsyncPipeline(settings AsyncSettings, stats *asyncCounters) *asyncPipeline {
+	p := &asyncPipeline{
+		settings: settings,
+		stats:    stats,
+		queue:    make([]asyncRecord, settings.QueueSize),
+		stopped:  make(chan struct{}),
+	}
+	p.cond = sync.NewCond(&p.mu)
+
+	go p.run()
+	if settings.FlushInterval > 0 {
+		go p.tick()
+	}
+	return p
+}
+
+// core returns a core that encodes the records with enc and queues them to be written to out
+func (p *asyncPipeline) core(enc zapcore.Encoder, out zapcore.WriteSyncer) zapcore.Core {
+	output := &asyncOutput{w: bufio.NewWriter(out), out: out}
+	p.outputs = append(p.outputs, output)
+	return &asyncCore{LevelEnabler: enableAll, enc: enc, out: output, pipeline: p}
+}
+
+// enqueue queues the record, applying the overflow policy if the queue is full
+func (p *asyncPipeline) enqueue(r asyncRecord) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for p.count == len(p.queue) && !p.closed {
+		switch p.settings.Policy {
+		case OverflowDropNewest:
+			p.drop(r)
+			return
+		case OverflowDropBelowLevel:
+			if r.level < p.settings.MinLevel {
+				p.drop(r)
+				return
+			}
+			p.wait()
+		case OverflowDropOldest:
+			p.drop(p.pop())
+			p.done++
+		default:
+			p.wait()
+		}
+	}
+
+	if p.closed {
+		// the queued records are already written
+		if _, err := r.out.out.Write(r.buf.Bytes()); err != nil && p.err == nil {
+			p.err = err
+		}
+		r.buf.Free()
+		p.stats.written.Add(1)
+		return
+	}
+
+	p.queue[(p.head+p.count)%len(p.queue)] = r
+	p.count++
+	p.accepted++
+	p.stats.enqueued.Add(1)
+	p.cond.Broadcast()
+}
+
+// wait waits for room in the queue
+func (p *asyncPipeline) wait() {
+	p.waiting++
+	p.cond.Wait()
+	p.waiting--
+}
+
+func (p *asyncPipeline) pop() asyncRecord {
+	r := p.queue[p.head]
+	p.queue[p.head] = asyncRecord{}
+	p.head = (p.head + 1) % len(p.queue)
+	p.count--
+	return r
+}
+
+func (p *asyncPipeline) drop(r asyncRecord) {
+	r.buf.Free()
+	p.stats.dropped.Add(1)
+}
+
+// run writes the queued records, until the pipeline is closed and drained
+func (p *asyncPipeline) run() {
+	defer close(p.stopped)
+
+	var batch []asyncRecord
+	for {
+		p.mu.Lock()
+		for p.count == 0 && !p.flushRequested && !p.closed {
+			p.cond.Wait()
+		}
+		batch = batch[:0]
+		for p.count > 0 {
+			batch = append(batch, p.pop())
+		}
+		flush := p.flushRequested || p.closed || p.settings.FlushInterval == 0
+		p.flushRequested = false
+		closed := p.closed
+		p.cond.Broadcast() // there is room in the queue
+		p.mu.Unlock()
+
+		err := p.write(batch, flush)
+
+		p.mu.Lock()
+		p.done += uint64(len(batch))
+		if flush {
+			p.flushed = p.done
+		}
+		if err != nil && p.err == nil {
+			p.err = err
+		}
+		p.cond.Broadcast()
+		p.mu.Unlock()
+
+		if closed {
+			return
+		}
+	}
+}
+
+// write writes the records to the buffers of their outputs, then flushes them if asked to
+func (p *asyncPipeline) write(batch []asyncRecord, flush bool) error {
+	var err error
+	for _, r := range batch {
+		if _, writeErr := r.out.w.Write(r.buf.Bytes()); writeErr != nil && err == nil {
+			err = writeErr
+		}
+		r.buf.Free()
+		p.stats.written.Add(1)
+	}
+
+	if flush {
+		for _, output := range p.outputs {
+			if flushErr := output.w.Flush(); flushErr != nil && err == nil {
+				err = flushErr
+			}
+		}
+	}
+	return err
+}
+
+// tick requests a flush of the outputs every FlushInterval
+func (p *asyncPipeline) tick() {
+	ticker := time.NewTicker(p.settings.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.mu.Lock()
+			p.flushRequested = true
+			p.cond.Broadcast()
+			p.mu.Unlock()
+		case <-p.stopped:
+			return
+		}
+	}
+}
+
+// Sync waits until the records queued so far are written and flushed, then syncs the outputs
+func (p *asyncPipeline) Sync() error {
+	p.mu.Lock()
+	target := p.accepted
+	p.flushRequested = true
+	p.cond.Broadcast()
+	for p.flushed < target && !p.closed {
+		p.cond.Wait()
+	}
+	closed := p.closed
+	err := p.err
+	p.err = nil
+	p.mu.Unlock()
+
+	if closed {
+		<-p.stopped
+	}
+	for _, output := range p.outputs {
+		if syncErr := output.out.Sync(); syncErr != nil && !isIgnorableSyncError(syncErr) {
+			err = errors.Join(err, syncErr)
+		}
+	}
+	return err
+}
+
+// Close writes the queued records and stops the goroutine; the next records are written synchronously
+func (p *asyncPipeline) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	<-p.stopped
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// asyncCore encodes the records like the zapcore ioCore, and queues them in the pipeline instead of writing them
+type asyncCore struct {
+	zapcore.LevelEnabler
+	enc      zapcore.Encoder
+	out      *asyncOutput
+	pipeline *asyncPipeline
+}
+
+func (c *asyncCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &asyncCore{LevelEnabler: c.LevelEnabler, enc: enc, out: c.out, pipeline: c.pipeline}
+}
+
+func (c *asyncCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *asyncCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	c.pipeline.enqueue(asyncRecord{out: c.out, buf: buf, level: ent.Level})
+
+	// the process is about to panic or exit, as the ioCore does
+	if ent.Level > zapcore.ErrorLevel {
+		return c.Sync()
+	}
+	return nil
+}
+
+func (c *asyncCore) Sync() error {
+	return c.pipeline.Sync()
+}
@@ -0,0 +1,178 @@
+package zapLogger
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+// gatedWriter blocks the writes until it is opened, and signals the first blocked write
+type gatedWriter struct {
+	mu      sync.Mutex
+	lines   strings.Builder
+	entered chan struct{}
+	gate    chan struct{}
+	once    sync.Once
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{entered: make(chan struct{}), gate: make(chan struct{})}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.entered) })
+	<-w.gate
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lines.Write(p)
+}
+
+func (w *gatedWriter) Sync() error {
+	return nil
+}
+
+func (w *gatedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lines.String()
+}
+
+// fillQueue logs "r1", waits until the goroutine of the pipeline is blocked writing it, then fills the queue of 2 with "r2" and "r3"
+func fillQueue(core zapcore.Core, w *gatedWriter) {
+	write(core, zapcore.InfoLevel, "r1")
+	<-w.entered
+	write(core, zapcore.InfoLevel, "r2")
+	write(core, zapcore.InfoLevel, "r3")
+}
+
+func write(core zapcore.Core, level zapcore.Level, message string) {
+	_ = core.Write(zapcore.Entry{Level: level, Message: message}, nil)
+}
+
+func newGatedPipeline(policy OverflowPolicy) (*asyncPipeline, zapcore.Core, *gatedWriter, *asyncCounters) {
+	stats := &asyncCounters{}
+	w := newGatedWriter()
+	p := newAsyncPipeline(AsyncSettings{QueueSize: 2, Policy: policy, MinLevel: zapcore.WarnLevel}, stats)
+	core := p.core(zapcore.NewConsoleEncoder(zapcore.EncoderConfig{MessageKey: "msg"}), w)
+	return p, core, w, stats
+}
+
+// blockedWriters returns the number of callers waiting for room in the queue
+func blockedWriters(p *asyncPipeline) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.waiting
+}
+
+func TestAsyncOverflowPolicies(t *testing.T) {
+	for _, test := range []struct {
+		policy   OverflowPolicy
+		expected string
+		dropped  uint64
+	}{
+		{OverflowBlock, "r1\nr2\nr3\nr4\nr5\n", 0},
+		{OverflowDropNewest, "r1\nr2\nr3\n", 2},
+		{OverflowDropOldest, "r1\nr4\nr5\n", 2},
+		{OverflowDropBelowLevel, "r1\nr2\nr3\nr5\n", 1},
+	} {
+		t.Run(test.policy.String(), func(t *testing.T) {
+			p, core, w, stats := newGatedPipeline(test.policy)
+			fillQueue(core, w)
+
+			// act: the writes that block wait in a goroutine until the gate opens
+			blocks := func(level zapcore.Level) bool {
+				return test.policy == OverflowBlock || test.policy == OverflowDropBelowLevel && level >= zapcore.WarnLevel
+			}
+			var wg sync.WaitGroup
+			for _, r := range []struct {
+				level   zapcore.Level
+				message string
+			}{{zapcore.InfoLevel, "r4"}, {zapcore.ErrorLevel, "r5"}} {
+				if !blocks(r.level) {
+					write(core, r.level, r.message)
+					continue
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					write(core, r.level, r.message)
+				}()
+				// keep the order of the blocked writes
+				assert.Eventually(t, func() bool { return blockedWriters(p) == 1 }, time.Second, time.Millisecond)
+				break
+			}
+			close(w.gate)
+			wg.Wait()
+			if test.policy == OverflowBlock {
+				write(core, zapcore.ErrorLevel, "r5")
+			}
+
+			// assert
+			assert.Nil(t, p.Sync())
+			assert.Nil(t, p.Close())
+			assert.Equal(t, test.expected, w.String())
+			assert.Equal(t, test.dropped, stats.dropped.Load())
+			assert.Equal(t, 5-test.dropped, stats.written.Load())
+		})
+	}
+}
+
+func TestAsyncWritesAfterClose(t *testing.T) {
+	p, core, w, stats := newGatedPipeline(OverflowBlock)
+	close(w.gate)
+
+	// act
+	write(core, zapcore.InfoLevel, "queued")
+	assert.Nil(t, p.Close())
+	write(core, zapcore.InfoLevel, "synchronous")
+
+	// assert
+	assert.Equal(t, "queued\nsynchronous\n", w.String())
+	assert.Equal(t, uint64(1), stats.enqueued.Load())
+	assert.Equal(t, uint64(2), stats.written.Load())
+}
+
+func (suite *ZapLogTestSuite) TestAsyncWrites() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort("").
+		WithAsync(AsyncSettings{QueueSize: 8, FlushInterval: time.Hour})
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	// act
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				suite.logger.Info("async record", "goroutine", g, "i", i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the records are flushed only on Sync, since the flush interval is long
+	suite.logger.Sync()
+	content, readErr := os.ReadFile(suite.tempLogFile.Name())
+	suite.logger.Info("drained on shutdown")
+	shutdownErr := suite.logger.Shutdown()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), readErr)
+	assert.Nil(suite.T(), shutdownErr)
+	assert.Equal(suite.T(), 200, strings.Count(string(content), `"msg":"async record"`))
+	assert.NotEmpty(suite.T(), findLogLine(suite.tempLogFile.Name(), "drained on shutdown"))
+	assert.Equal(suite.T(), AsyncStats{Enqueued: 201, Written: 201}, suite.logger.AsyncStats())
+}
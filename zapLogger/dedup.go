@@ -0,0 +1,148 @@
+package zapLogger
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// dedupState is the last record written by a dedupCore and the cores derived from it, with its repetitions
+type dedupState struct {
+	window atomic.Int64 // time.Duration; 0 disables the collapsing
+
+	mu       sync.Mutex
+	last     zapcore.Entry
+	lastKey  []byte       // the encoded fields of the last record, including the fields added with `With`
+	lastCore zapcore.Core // the core that wrote the last record, to which its summary is written
+	repeated int          // repetitions of the last record, not written yet
+	lastSeen time.Time    // time of the last repetition
+	timer    *time.Timer  // writes the summary once the window of the last record expires, set on its first repetition
+}
+
+// keyEncoder encodes the fields that identify a record; it is only cloned, so it is safe for concurrent use
+var keyEncoder = zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+
+func newDedupCore(core zapcore.Core, window time.Duration) *dedupCore {
+	state := &dedupState{}
+	state.window.Store(int64(window))
+	return &dedupCore{Core: core, state: state, enc: keyEncoder.Clone()}
+}
+
+// dedupCore collapses the consecutive records of the same level, logger, message and fields, logged within a window
+// from the first one: only the first record is written, followed by a summary of its repetitions
+// (`last message repeated N times`) when the window expires, a different record is logged, or the core is synced.
+// The records above ErrorLevel are never collapsed.
+type dedupCore struct {
+	zapcore.Core
+	state *dedupState
+	enc   zapcore.Encoder // encodes the fields added with `With`, as part of the key of the records
+}
+
+// setWindow changes the window, once the pending repetitions are written
+func (c *dedupCore) setWindow(window time.Duration) {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+	c.state.flush()
+	c.state.window.Store(int64(window))
+}
+
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &dedupCore{Core: c.Core.With(fields), state: c.state, enc: enc}
+}
+
+func (c *dedupCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.state.window.Load() == 0 {
+		return c.Core.Check(ent, ce)
+	}
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *dedupCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(zapcore.Entry{}, fields)
+	if err != nil {
+		return c.Core.Write(ent, fields)
+	}
+	defer buf.Free()
+
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	window := time.Duration(s.window.Load())
+	if s.lastCore != nil && ent.Level <= zapcore.ErrorLevel && ent.Time.Sub(s.last.Time) < window && s.isLast(ent, buf.Bytes()) {
+		s.repeated++
+		s.lastSeen = ent.Time
+		if s.timer == nil {
+			s.timer = time.AfterFunc(time.Until(s.last.Time.Add(window)), s.expire)
+		}
+		return nil
+	}
+
+	s.flush()
+	s.last, s.lastCore, s.lastKey = ent, c.Core, append(s.lastKey[:0], buf.Bytes()...)
+	return c.Core.Write(ent, fields)
+}
+
+func (c *dedupCore) Sync() error {
+	c.state.mu.Lock()
+	c.state.flush()
+	c.state.mu.Unlock()
+	return c.Core.Sync()
+}
+
+// isLast reports whether the record repeats the last one
+func (s *dedupState) isLast(ent zapcore.Entry, key []byte) bool {
+	return ent.Level == s.last.Level &&
+		ent.LoggerName == s.last.LoggerName &&
+		ent.Message == s.last.Message &&
+		bytes.Equal(key, s.lastKey)
+}
+
+// expire writes the summary of the repetitions of the last record once its window is over,
+// so that it is not delayed until the next record after a burst
+func (s *dedupState) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// a late timer of a record already summarized finds the window of the next one still open
+	if time.Now().Before(s.last.Time.Add(time.Duration(s.window.Load()))) {
+		return
+	}
+	s.flush()
+}
+
+// flush writes the summary of the repetitions of the last record, if any, and forgets it
+func (s *dedupState) flush() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.lastCore == nil {
+		return
+	}
+	if s.repeated > 0 {
+		summary := zapcore.Entry{
+			Level:      s.last.Level,
+			Time:       s.lastSeen,
+			LoggerName: s.last.LoggerName,
+			Message:    fmt.Sprintf("last message repeated %d times", s.repeated),
+		}
+		_ = s.lastCore.Write(summary, []zapcore.Field{
+			zap.Int(RepeatedKey, s.repeated),
+			zap.Duration(RepeatSpanKey, s.lastSeen.Sub(s.last.Time)),
+		})
+	}
+	s.lastCore, s.repeated = nil, 0
+}
@@ -0,0 +1,101 @@
+package zapLogger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDedupCore(t *testing.T) {
+	observed, logs := observer.New(TraceLevel)
+	core := newDedupCore(observed, time.Minute)
+	start := time.Now()
+	write := func(core zapcore.Core, offset time.Duration, message string, fields ...zapcore.Field) {
+		ent := zapcore.Entry{Level: zapcore.WarnLevel, Time: start.Add(offset), Message: message}
+		if ce := core.Check(ent, nil); ce != nil {
+			ce.Write(fields...)
+		}
+	}
+	child := core.With([]zapcore.Field{zap.String("conn", "a")})
+
+	// act
+	for i := 0; i < 5; i++ {
+		write(core, time.Duration(i)*time.Second, "retrying", zap.Int("attempt", 1))
+	}
+	write(core, 5*time.Second, "retrying", zap.Int("attempt", 2))  // other fields
+	write(child, 6*time.Second, "retrying", zap.Int("attempt", 2)) // other context
+	write(child, 7*time.Second, "retrying", zap.Int("attempt", 2))
+	write(child, 2*time.Minute, "retrying", zap.Int("attempt", 2)) // out of the window
+	assert.Nil(t, core.Sync())
+
+	// assert
+	var messages []string
+	for _, entry := range logs.All() {
+		messages = append(messages, entry.Message)
+	}
+	assert.Equal(t, []string{
+		"retrying",
+		"last message repeated 4 times",
+		"retrying",
+		"retrying",
+		"last message repeated 1 times",
+		"retrying",
+	}, messages)
+
+	summary := logs.All()[1]
+	assert.Equal(t, zapcore.WarnLevel, summary.Level)
+	assert.Equal(t, map[string]any{RepeatedKey: int64(4), RepeatSpanKey: 4 * time.Second}, summary.ContextMap())
+	assert.Equal(t, "a", logs.All()[4].ContextMap()["conn"])
+}
+
+func TestDedupCoreWindowExpiry(t *testing.T) {
+	observed, logs := observer.New(TraceLevel)
+	core := newDedupCore(observed, 50*time.Millisecond)
+
+	// act
+	for i := 0; i < 3; i++ {
+		if ce := core.Check(zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: "burst"}, nil); ce != nil {
+			ce.Write()
+		}
+	}
+
+	// assert: the summary is written once the window expires, without any other record nor sync
+	assert.Eventually(t, func() bool {
+		return logs.FilterMessage("last message repeated 2 times").Len() == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 2, logs.Len())
+}
+
+func (suite *ZapLogTestSuite) TestDedup() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort("").
+		WithDedup(time.Minute)
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	// act
+	for i := 0; i < 100; i++ {
+		suite.logger.Warn("disk almost full", "free", "1%")
+	}
+	suite.logger.Sync()
+	repeated := findLogLine(suite.tempLogFile.Name(), "last message repeated 99 times")
+
+	suite.logger.Info("once")
+	suite.logger.Info("once")
+	shutdownErr := suite.logger.Shutdown()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), shutdownErr)
+	assert.Contains(suite.T(), repeated, `"level":"warn"`)
+	assert.Contains(suite.T(), repeated, `"repeated":99`)
+	assert.NotEmpty(suite.T(), findLogLine(suite.tempLogFile.Name(), "last message repeated 1 times"))
+}
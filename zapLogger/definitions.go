@@ -1,6 +1,11 @@
 package zapLogger
 
-import "time"
+import (
+	"time"
+
+	"github.com/vlbarou/logger/logapi"
+	"go.uber.org/zap/zapcore"
+)
 
 const (
 	MaxSizeMB               = 10
@@ -12,4 +17,39 @@ const (
 	LoggerServerPort        = "8081"
 	GracefulShutdownTimeout = 5 * time.Second
 	LogServerURI            = "/loglevel"
+	SamplingSummaryInterval = time.Minute   // default interval of the summaries of the suppressed records
+	SuppressedKey           = "suppressed"  // key of the number of suppressed records, in their summary
+	RepeatedKey             = "repeated"    // key of the number of repetitions of a record, in their summary
+	RepeatSpanKey           = "repeat_span" // key of the time from a record to its last repetition, in their summary
+)
+
+// The security of the log server, see AdminSettings
+const (
+	AdminHost            = "127.0.0.1"       // default interface of the log server
+	AdminAPIPrefix       = "/logger/v1"      // prefix of the JSON API of the log server
+	AdminSignatureHeader = "X-Log-Signature" // hex-encoded HMAC-SHA256 of a request to the log server, see SignAdminRequest
+	AdminTimestampHeader = "X-Log-Timestamp" // Unix time of the signature of a request to the log server
+	AdminSignatureMaxAge = 5 * time.Minute   // maximum difference between the time of a signature and the time of the server
 )
+
+// The outputs a logger can write to
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+)
+
+// Settings are the settings of the outputs and the global level, which can be replaced at runtime with Reconfigure
+type Settings struct {
+	LogFile     string
+	LogRotation bool
+	MaxSizeMB   int
+	MaxBackups  int
+	MaxAge      int // days
+	Level       zapcore.Level
+	Outputs     []string
+	Async       AsyncSettings
+	Sampling    SamplingSettings
+	DedupWindow time.Duration // window of the collapsing of the consecutive duplicates; 0 disables it
+	Redaction   logapi.RedactionConfig
+}
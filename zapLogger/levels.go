@@ -0,0 +1,153 @@
+package zapLogger
+
+import (
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levelRegistry holds the runtime levels of the named loggers.
+// A named logger without an override inherits the level of its closest ancestor (e.g. "db.pool" inherits from "db"),
+// and ultimately the global atomic level.
+type levelRegistry struct {
+	global    zap.AtomicLevel
+	mu        sync.RWMutex
+	overrides map[string]zapcore.Level
+}
+
+func newLevelRegistry(global zap.AtomicLevel) *levelRegistry {
+	return &levelRegistry{
+		global:    global,
+		overrides: make(map[string]zapcore.Level),
+	}
+}
+
+// level returns the effective level of the named logger. The empty name refers to the global level
+func (r *levelRegistry) level(name string) zapcore.Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for n := name; n != ""; n = parentName(n) {
+		if lvl, ok := r.overrides[n]; ok {
+			return lvl
+		}
+	}
+	return r.global.Level()
+}
+
+func (r *levelRegistry) setLevel(name string, lvl zapcore.Level) {
+	if name == "" {
+		r.global.SetLevel(lvl)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overrides[name] = lvl
+}
+
+// overridden returns a copy of the levels set for the named loggers
+func (r *levelRegistry) overridden() map[string]zapcore.Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	levels := make(map[string]zapcore.Level, len(r.overrides))
+	for name, lvl := range r.overrides {
+		levels[name] = lvl
+	}
+	return levels
+}
+
+func (r *levelRegistry) resetLevel(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.overrides, name)
+}
+
+// parentName returns the name of the parent logger, e.g. "db" for "db.pool" and "" for "db"
+func parentName(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// namedLevelCore filters the entries of the wrapped core according to the effective level of a named logger.
+// The wrapped cores must enable every level, since the decision is taken here.
+type namedLevelCore struct {
+	zapcore.Core
+	name   string
+	levels *levelRegistry
+}
+
+func (c *namedLevelCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= c.levels.level(c.name)
+}
+
+func (c *namedLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &namedLevelCore{
+		Core:   c.Core.With(fields),
+		name:   c.name,
+		levels: c.levels,
+	}
+}
+
+func (c *namedLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// renameLevelCore makes a core obey the level of the given named logger
+func renameLevelCore(core zapcore.Core, name string) zapcore.Core {
+	if c, ok := core.(*namedLevelCore); ok {
+		return &namedLevelCore{
+			Core:   c.Core,
+			name:   name,
+			levels: c.levels,
+		}
+	}
+	return core
+}
+
+// enableAll is the level enabler of the underlying cores; levels are checked by namedLevelCore
+var enableAll = zap.LevelEnablerFunc(func(zapcore.Level) bool { return true })
+
+// TraceLevel is a custom level, below zap's DebugLevel
+const TraceLevel = zapcore.DebugLevel - 1
+
+// parseLevel parses the zap levels, and "trace"
+func parseLevel(text string) (zapcore.Level, error) {
+	if strings.EqualFold(text, "trace") {
+		return TraceLevel, nil
+	}
+
+	var level zapcore.Level
+	err := level.UnmarshalText([]byte(text))
+	return level, err
+}
+
+// levelName returns the lowercase name of a level, aware of TraceLevel
+func levelName(level zapcore.Level) string {
+	if level == TraceLevel {
+		return "trace"
+	}
+	return level.String()
+}
+
+// lowercaseLevelEncoder is zapcore.LowercaseLevelEncoder, aware of TraceLevel
+func lowercaseLevelEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(levelName(level))
+}
+
+// capitalColorLevelEncoder is zapcore.CapitalColorLevelEncoder, aware of TraceLevel (in magenta)
+func capitalColorLevelEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	if level == TraceLevel {
+		enc.AppendString("\x1b[35mTRACE\x1b[0m")
+		return
+	}
+	zapcore.CapitalColorLevelEncoder(level, enc)
+}
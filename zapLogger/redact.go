@@ -0,0 +1,140 @@
+package zapLogger
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/vlbarou/logger/logapi"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactCore redacts the sensitive values of the records (see logapi.Redactor) before writing them.
+// The redactor is shared with the cores derived with `With`, and replaced by Reconfigure, so the fields added
+// with `With` are kept as they are and redacted by the current redactor, like the fields of the records.
+type redactCore struct {
+	zapcore.Core                                     // without the fields added with `With`
+	redactor     *atomic.Pointer[logapi.Redactor]    // nil if the redaction is disabled
+	fields       []zapcore.Field                     // fields added with `With`, not redacted
+	cache        atomic.Pointer[redactedContextCore] // the wrapped core with the fields redacted by the current redactor
+}
+
+// redactedContextCore is the wrapped core with the fields added with `With` redacted by a redactor
+type redactedContextCore struct {
+	redactor *logapi.Redactor
+	core     zapcore.Core
+}
+
+func newRedactCore(core zapcore.Core, redactor *logapi.Redactor) *redactCore {
+	c := &redactCore{Core: core, redactor: new(atomic.Pointer[logapi.Redactor])}
+	c.redactor.Store(redactor)
+	return c
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{
+		Core:     c.Core,
+		redactor: c.redactor,
+		fields:   append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+// core returns the wrapped core with the fields added with `With`, redacted by r
+func (c *redactCore) core(r *logapi.Redactor) zapcore.Core {
+	if len(c.fields) == 0 {
+		return c.Core
+	}
+	if cached := c.cache.Load(); cached != nil && cached.redactor == r {
+		return cached.core
+	}
+
+	fields := c.fields
+	if r != nil {
+		fields = redactFields(r, fields)
+	}
+	cached := &redactedContextCore{redactor: r, core: c.Core.With(fields)}
+	c.cache.Store(cached)
+	return cached.core
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if r := c.redactor.Load(); r == nil {
+		return c.core(nil).Check(ent, ce)
+	}
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	r := c.redactor.Load()
+	if r != nil {
+		ent.Message, _ = r.String(ent.Message)
+		fields = redactFields(r, fields)
+	}
+	return c.core(r).Write(ent, fields)
+}
+
+// redactFields returns the fields with the sensitive values redacted; the given fields are not modified
+func redactFields(r *logapi.Redactor, fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, 0, len(fields))
+	for _, f := range fields {
+		if f, keep := redactField(r, f); keep {
+			redacted = append(redacted, f)
+		}
+	}
+	return redacted
+}
+
+// redactField redacts a field according to its key, then to its value. The result is false if the field must be dropped
+func redactField(r *logapi.Redactor, f zapcore.Field) (zapcore.Field, bool) {
+	if f.Type == zapcore.SkipType || f.Type == zapcore.NamespaceType || f.Type == zapcore.InlineMarshalerType {
+		return f, true
+	}
+	if r.SensitiveKey(f.Key) {
+		if r.Mode() == logapi.RedactDrop {
+			return f, false
+		}
+		return zap.String(f.Key, r.Replace(fmt.Sprint(logapi.FieldValue(f)))), true
+	}
+
+	if redactable, ok := f.Interface.(logapi.Redactable); ok {
+		return redactValue(r, f.Key, redactable)
+	}
+
+	switch f.Type {
+	case zapcore.StringType:
+		return redactString(r, f, f.String)
+	case zapcore.ByteStringType:
+		return redactString(r, f, string(f.Interface.([]byte)))
+	case zapcore.StringerType:
+		return redactString(r, f, logapi.FieldValue(f).(string))
+	case zapcore.ErrorType:
+		return redactString(r, f, f.Interface.(error).Error())
+	case zapcore.ReflectType:
+		return redactValue(r, f.Key, f.Interface)
+	case zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType:
+		return redactValue(r, f.Key, logapi.FieldValue(f))
+	default:
+		return f, true // numbers, booleans, times and durations
+	}
+}
+
+// redactString replaces the field by the redacted string, if the string has sensitive values
+func redactString(r *logapi.Redactor, f zapcore.Field, s string) (zapcore.Field, bool) {
+	redacted, found := r.String(s)
+	if !found {
+		return f, true
+	}
+	if r.Mode() == logapi.RedactDrop {
+		return f, false
+	}
+	return zap.String(f.Key, redacted), true
+}
+
+// redactValue replaces the field by the redacted value, walked as JSON
+func redactValue(r *logapi.Redactor, key string, value any) (zapcore.Field, bool) {
+	redacted, keep := r.Walk(value)
+	return zap.Any(key, redacted), keep
+}
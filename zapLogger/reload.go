@@ -0,0 +1,125 @@
+package zapLogger
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// coreVersion is a generation of the sinks, replaced as a whole by Reconfigure
+type coreVersion struct {
+	core       zapcore.Core
+	closers    []io.Closer // the files of this generation, closed once it is replaced
+	generation uint64
+}
+
+// coreState is shared by a reloadableCore and all the cores derived from it with `With`
+type coreState struct {
+	mu      sync.RWMutex // held for reading while writing, and for writing while swapping the generations
+	current atomic.Pointer[coreVersion]
+}
+
+// reloadableCore writes to the current generation of the sinks.
+// Writes hold a read lock, so a generation is never closed while it is written to: every record that passes
+// the level check is written exactly once, either to the old or to the new sinks.
+type reloadableCore struct {
+	state  *coreState
+	fields []zapcore.Field             // fields added with `With`, re-applied to every new generation
+	cache  atomic.Pointer[coreVersion] // the current generation with the fields applied
+}
+
+func newReloadableCore(core zapcore.Core, closers []io.Closer) *reloadableCore {
+	state := &coreState{}
+	state.current.Store(&coreVersion{core: core, closers: closers})
+	return &reloadableCore{state: state}
+}
+
+// swap replaces the sinks, then flushes and closes the replaced ones
+func (c *reloadableCore) swap(core zapcore.Core, closers []io.Closer) error {
+	c.state.mu.Lock()
+	old := c.state.current.Load()
+	c.state.current.Store(&coreVersion{core: core, closers: closers, generation: old.generation + 1})
+	c.state.mu.Unlock()
+
+	var err error
+	if syncErr := old.core.Sync(); syncErr != nil && !isIgnorableSyncError(syncErr) {
+		err = syncErr
+	}
+	for _, closer := range old.closers {
+		if closeErr := closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// closers returns the closers of the current generation
+func (c *reloadableCore) closers() []io.Closer {
+	return c.state.current.Load().closers
+}
+
+// rotate rotates the log files of the current generation. It returns false if there are none, as the rotation is disabled.
+func (c *reloadableCore) rotate() (bool, error) {
+	c.state.mu.RLock()
+	defer c.state.mu.RUnlock()
+
+	rotated := false
+	var err error
+	for _, closer := range c.state.current.Load().closers {
+		if file, ok := closer.(*lumberjack.Logger); ok {
+			rotated = true
+			err = errors.Join(err, file.Rotate())
+		}
+	}
+	return rotated, err
+}
+
+// core returns the current generation, with the fields of this core applied
+func (c *reloadableCore) core() zapcore.Core {
+	current := c.state.current.Load()
+	if len(c.fields) == 0 {
+		return current.core
+	}
+
+	if cached := c.cache.Load(); cached != nil && cached.generation == current.generation {
+		return cached.core
+	}
+	derived := &coreVersion{core: current.core.With(c.fields), generation: current.generation}
+	c.cache.Store(derived)
+	return derived.core
+}
+
+func (c *reloadableCore) Enabled(lvl zapcore.Level) bool {
+	return c.core().Enabled(lvl)
+}
+
+func (c *reloadableCore) With(fields []zapcore.Field) zapcore.Core {
+	return &reloadableCore{
+		state:  c.state,
+		fields: append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+// Check registers this core, rather than the current generation, so that the generation is picked when writing
+func (c *reloadableCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *reloadableCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.state.mu.RLock()
+	defer c.state.mu.RUnlock()
+	return c.core().Write(ent, fields)
+}
+
+func (c *reloadableCore) Sync() error {
+	c.state.mu.RLock()
+	defer c.state.mu.RUnlock()
+	return c.state.current.Load().core.Sync()
+}
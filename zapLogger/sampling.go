@@ -0,0 +1,334 @@
+package zapLogger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Sampler is the algorithm of a sampling rule
+type Sampler int
+
+const (
+	SamplerPerKey Sampler = iota // counts the records of each named logger, level and message exactly
+	SamplerZap                   // zap's sampler: counts the records by level and hash of the message, in fixed memory but with collisions
+)
+
+func (s Sampler) String() string {
+	switch s {
+	case SamplerPerKey:
+		return "per_key"
+	case SamplerZap:
+		return "zap"
+	default:
+		return fmt.Sprintf("Sampler(%d)", int(s))
+	}
+}
+
+// IsValid reports whether s is one of the defined samplers
+func (s Sampler) IsValid() bool {
+	return s == SamplerPerKey || s == SamplerZap
+}
+
+// SamplingRule logs the First records of a message in each Interval, then every Thereafter-th one
+type SamplingRule struct {
+	First      int
+	Thereafter int           // 0 drops all the records after the first ones
+	Interval   time.Duration // 0 disables the sampling
+	Sampler    Sampler
+}
+
+// SamplingSettings limit the records of a repeated message. The rule of a record is the rule of its named logger,
+// or of the closest ancestor that has one, else the rule of its level, else the default rule.
+// The suppressed records are counted, and reported every SummaryInterval by a summary record.
+type SamplingSettings struct {
+	Default         SamplingRule
+	Levels          map[zapcore.Level]SamplingRule
+	Loggers         map[string]SamplingRule
+	SummaryInterval time.Duration // SamplingSummaryInterval if 0
+}
+
+// enabled reports whether any rule samples the records
+func (s SamplingSettings) enabled() bool {
+	if s.Default.Interval > 0 {
+		return true
+	}
+	for _, rule := range s.Levels {
+		if rule.Interval > 0 {
+			return true
+		}
+	}
+	for _, rule := range s.Loggers {
+		if rule.Interval > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// sampledEntry is returned by the zap samplers when a record is sampled, see samplingRule.zap
+var sampledEntry = new(zapcore.CheckedEntry)
+
+// decisionCore is the core wrapped by the zap samplers, which only take the sampling decision
+type decisionCore struct {
+	zapcore.LevelEnabler
+}
+
+func (c decisionCore) With([]zapcore.Field) zapcore.Core { return c }
+
+func (c decisionCore) Check(zapcore.Entry, *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return sampledEntry
+}
+
+func (c decisionCore) Write(zapcore.Entry, []zapcore.Field) error { return nil }
+
+func (c decisionCore) Sync() error { return nil }
+
+// samplingRule is a rule with its zap sampler, if it uses one
+type samplingRule struct {
+	SamplingRule
+	zap zapcore.Core
+}
+
+// samplingRules are the compiled SamplingSettings
+type samplingRules struct {
+	def     *samplingRule
+	levels  map[zapcore.Level]*samplingRule
+	loggers map[string]*samplingRule
+}
+
+// rule returns the rule of the record, or nil if it is not sampled
+func (r *samplingRules) rule(ent zapcore.Entry) *samplingRule {
+	for name := ent.LoggerName; name != ""; name = parentName(name) {
+		if rule, ok := r.loggers[name]; ok {
+			return rule
+		}
+	}
+	if rule, ok := r.levels[ent.Level]; ok {
+		return rule
+	}
+	return r.def
+}
+
+// samplingKey identifies the records counted together
+type samplingKey struct {
+	logger  string
+	level   zapcore.Level
+	message string
+}
+
+type samplingCount struct {
+	expires    time.Time // end of the current interval
+	n          int       // records of the current interval
+	suppressed uint64    // records suppressed since the last summary
+}
+
+// sampler takes the sampling decisions of a logger and writes the summaries of the suppressed records.
+// Its rules are replaced by Reconfigure.
+type sampler struct {
+	out zapcore.Core // the core the summaries are written to, past the levels and the sampling
+
+	rules  atomic.Pointer[samplingRules] // nil if no rule samples the records
+	mu     sync.Mutex
+	counts map[samplingKey]*samplingCount
+
+	lifecycle sync.Mutex    // serializes configure and close
+	stop      chan struct{} // stops the summaries, nil if there are none
+	stopped   chan struct{}
+}
+
+func newSampler(out zapcore.Core) *sampler {
+	return &sampler{out: out, counts: make(map[samplingKey]*samplingCount)}
+}
+
+// configure replaces the rules, once the records suppressed so far are summarized
+func (s *sampler) configure(settings SamplingSettings) {
+	s.lifecycle.Lock()
+	defer s.lifecycle.Unlock()
+	s.stopSummaries()
+
+	s.mu.Lock()
+	clear(s.counts)
+	s.mu.Unlock()
+
+	if !settings.enabled() {
+		s.rules.Store(nil)
+		return
+	}
+
+	rules := &samplingRules{
+		def:     s.compile(settings.Default),
+		levels:  make(map[zapcore.Level]*samplingRule, len(settings.Levels)),
+		loggers: make(map[string]*samplingRule, len(settings.Loggers)),
+	}
+	for level, rule := range settings.Levels {
+		rules.levels[level] = s.compile(rule)
+	}
+	for name, rule := range settings.Loggers {
+		rules.loggers[name] = s.compile(rule)
+	}
+	s.rules.Store(rules)
+
+	interval := settings.SummaryInterval
+	if interval <= 0 {
+		interval = SamplingSummaryInterval
+	}
+	s.stop, s.stopped = make(chan struct{}), make(chan struct{})
+	go s.summarize(interval, s.stop, s.stopped)
+}
+
+// compile returns the rule, or nil if it doesn't sample the records
+func (s *sampler) compile(rule SamplingRule) *samplingRule {
+	if rule.Interval <= 0 {
+		return nil
+	}
+
+	compiled := &samplingRule{SamplingRule: rule}
+	if rule.Sampler == SamplerZap {
+		compiled.zap = zapcore.NewSamplerWithOptions(decisionCore{LevelEnabler: enableAll}, rule.Interval, rule.First, rule.Thereafter,
+			zapcore.SamplerHook(func(ent zapcore.Entry, decision zapcore.SamplingDecision) {
+				if decision&zapcore.LogDropped != 0 {
+					s.suppress(ent)
+				}
+			}))
+	}
+	return compiled
+}
+
+// sample reports whether the record is logged
+func (s *sampler) sample(ent zapcore.Entry) bool {
+	rules := s.rules.Load()
+	if rules == nil {
+		return true
+	}
+	rule := rules.rule(ent)
+	if rule == nil {
+		return true
+	}
+
+	if rule.zap != nil {
+		// zap's sampler ignores the levels below DebugLevel, which are counted as DebugLevel
+		ent.Level = max(ent.Level, zapcore.DebugLevel)
+		return rule.zap.Check(ent, nil) == sampledEntry
+	}
+
+	key := samplingKey{logger: ent.LoggerName, level: ent.Level, message: ent.Message}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count, ok := s.counts[key]
+	if !ok {
+		count = &samplingCount{}
+		s.counts[key] = count
+	}
+	if !ent.Time.Before(count.expires) {
+		count.expires, count.n = ent.Time.Add(rule.Interval), 0
+	}
+
+	count.n++
+	if count.n <= rule.First || (rule.Thereafter > 0 && (count.n-rule.First)%rule.Thereafter == 0) {
+		return true
+	}
+	count.suppressed++
+	return false
+}
+
+// suppress counts a record suppressed by a zap sampler
+func (s *sampler) suppress(ent zapcore.Entry) {
+	key := samplingKey{logger: ent.LoggerName, level: ent.Level, message: ent.Message}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count, ok := s.counts[key]
+	if !ok {
+		count = &samplingCount{}
+		s.counts[key] = count
+	}
+	count.suppressed++
+}
+
+// summarize writes the summaries every interval, until stopped
+func (s *sampler) summarize(interval time.Duration, stop chan struct{}, stopped chan struct{}) {
+	defer close(stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flush()
+		case <-stop:
+			s.flush()
+			return
+		}
+	}
+}
+
+// flush writes a summary record for each message suppressed since the last summary, and forgets the expired counts
+func (s *sampler) flush() {
+	now := time.Now()
+	var summaries []zapcore.Entry
+	var suppressed []uint64
+
+	s.mu.Lock()
+	for key, count := range s.counts {
+		if count.suppressed > 0 {
+			summaries = append(summaries, zapcore.Entry{
+				Level:      key.level,
+				Time:       now,
+				LoggerName: key.logger,
+				Message:    fmt.Sprintf("suppressed %d occurrences of %q", count.suppressed, key.message),
+			})
+			suppressed = append(suppressed, count.suppressed)
+			count.suppressed = 0
+		}
+		if !now.Before(count.expires) {
+			delete(s.counts, key)
+		}
+	}
+	s.mu.Unlock()
+
+	for i, ent := range summaries {
+		if ce := s.out.Check(ent, nil); ce != nil {
+			ce.Write(zap.Uint64(SuppressedKey, suppressed[i]))
+		}
+	}
+}
+
+// close writes the pending summaries and stops writing them
+func (s *sampler) close() {
+	s.lifecycle.Lock()
+	defer s.lifecycle.Unlock()
+	s.stopSummaries()
+}
+
+func (s *sampler) stopSummaries() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.stopped
+	s.stop, s.stopped = nil, nil
+}
+
+// samplingCore drops the records that the sampler doesn't sample
+type samplingCore struct {
+	zapcore.Core
+	sampler *sampler
+}
+
+func (c *samplingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &samplingCore{Core: c.Core.With(fields), sampler: c.sampler}
+}
+
+func (c *samplingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.sampler.sample(ent) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
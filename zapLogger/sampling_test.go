@@ -0,0 +1,152 @@
+package zapLogger
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vlbarou/logger/logapi"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newObservedSampler(settings SamplingSettings) (*sampler, *observer.ObservedLogs) {
+	core, logs := observer.New(TraceLevel)
+	s := newSampler(core)
+	s.configure(settings)
+	return s, logs
+}
+
+// sampled returns the number of records sampled out of n records of the same message, logged at the given time
+func sampled(s *sampler, ent zapcore.Entry, n int) int {
+	count := 0
+	for i := 0; i < n; i++ {
+		if s.sample(ent) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestSamplingRules(t *testing.T) {
+	for _, kind := range []Sampler{SamplerPerKey, SamplerZap} {
+		t.Run(kind.String(), func(t *testing.T) {
+			s, logs := newObservedSampler(SamplingSettings{
+				Default: SamplingRule{First: 3, Thereafter: 10, Interval: time.Hour, Sampler: kind},
+				Levels: map[zapcore.Level]SamplingRule{
+					zapcore.ErrorLevel: {}, // the errors are never sampled
+				},
+				Loggers: map[string]SamplingRule{
+					"db":      {First: 1, Interval: time.Hour, Sampler: kind},
+					"db.pool": {},
+				},
+				SummaryInterval: time.Hour,
+			})
+			now := time.Now()
+
+			// act
+			warn := sampled(s, zapcore.Entry{Level: zapcore.WarnLevel, Time: now, Message: "retrying"}, 100)
+			errors := sampled(s, zapcore.Entry{Level: zapcore.ErrorLevel, Time: now, Message: "retrying"}, 100)
+			db := sampled(s, zapcore.Entry{Level: zapcore.WarnLevel, Time: now, LoggerName: "db.conn", Message: "retrying"}, 100)
+			pool := sampled(s, zapcore.Entry{Level: zapcore.WarnLevel, Time: now, LoggerName: "db.pool", Message: "retrying"}, 100)
+			s.close()
+
+			// assert
+			assert.Equal(t, 3+9, warn) // the first 3, then the 13th, 23rd, ... 93rd
+			assert.Equal(t, 100, errors)
+			assert.Equal(t, 1, db)
+			assert.Equal(t, 100, pool)
+
+			summaries := map[string]string{}
+			for _, entry := range logs.All() {
+				summaries[entry.LoggerName] = fmt.Sprintf("%s %s %v", entry.Level, entry.Message, entry.ContextMap()[SuppressedKey])
+			}
+			assert.Equal(t, map[string]string{
+				"":        `warn suppressed 88 occurrences of "retrying" 88`,
+				"db.conn": `warn suppressed 99 occurrences of "retrying" 99`,
+			}, summaries)
+		})
+	}
+}
+
+func TestSamplingInterval(t *testing.T) {
+	s, logs := newObservedSampler(SamplingSettings{
+		Default:         SamplingRule{First: 2, Interval: time.Second},
+		SummaryInterval: time.Hour,
+	})
+	start := time.Now()
+
+	// act
+	first := sampled(s, zapcore.Entry{Level: zapcore.InfoLevel, Time: start, Message: "tick"}, 5)
+	next := sampled(s, zapcore.Entry{Level: zapcore.InfoLevel, Time: start.Add(time.Second), Message: "tick"}, 5)
+	other := sampled(s, zapcore.Entry{Level: zapcore.InfoLevel, Time: start, Message: "tock"}, 5)
+	s.flush()
+
+	// assert
+	assert.Equal(t, 2, first)
+	assert.Equal(t, 2, next)
+	assert.Equal(t, 2, other)
+	assert.Equal(t, 1, logs.FilterMessage(`suppressed 6 occurrences of "tick"`).Len()) // since the last summary
+	assert.Equal(t, 1, logs.FilterMessage(`suppressed 3 occurrences of "tock"`).Len())
+}
+
+func TestSamplingSummariesArePeriodic(t *testing.T) {
+	s, logs := newObservedSampler(SamplingSettings{
+		Default:         SamplingRule{First: 1, Interval: time.Hour},
+		SummaryInterval: 10 * time.Millisecond,
+	})
+	defer s.close()
+
+	// act
+	sampled(s, zapcore.Entry{Level: zapcore.WarnLevel, Time: time.Now(), Message: "flood"}, 10)
+
+	// assert
+	assert.Eventually(t, func() bool {
+		return logs.FilterMessage(`suppressed 9 occurrences of "flood"`).Len() == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func (suite *ZapLogTestSuite) TestSampling() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort("").
+		WithSampling(SamplingSettings{
+			Default:         SamplingRule{First: 2, Thereafter: 100, Interval: time.Hour},
+			Loggers:         map[string]SamplingRule{"audit": {}},
+			SummaryInterval: time.Hour,
+		}).
+		WithRedaction(logapi.RedactionConfig{Patterns: []logapi.ValuePattern{logapi.EmailPattern}})
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	// act
+	for i := 0; i < 250; i++ {
+		suite.logger.Warn("retry {attempt}", "attempt", i)
+		suite.logger.Named("audit").Info("access", "i", i)
+	}
+	for i := 0; i < 5; i++ {
+		suite.logger.Info("login of bob@example.com")
+	}
+	shutdownErr := suite.logger.Shutdown()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), shutdownErr)
+	content, readErr := os.ReadFile(suite.tempLogFile.Name())
+	assert.Nil(suite.T(), readErr)
+	assert.Equal(suite.T(), 4, strings.Count(string(content), `"msg":"retry `)) // 0, 1, 101 and 201
+	assert.Equal(suite.T(), 250, strings.Count(string(content), `"msg":"access"`))
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), `suppressed 246 occurrences of \"retry {attempt}\"`), `"suppressed":246`)
+
+	// the summaries are redacted and counted like the other records
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), `suppressed 3 occurrences of \"login of ***\"`), `"suppressed":3`)
+	assert.NotContains(suite.T(), string(content), "bob@example.com")
+	assert.Equal(suite.T(), uint64(4+1), suite.logger.levelCounters.counts()["warn"])
+	assert.Equal(suite.T(), uint64(250+2+1), suite.logger.levelCounters.counts()["info"])
+}
@@ -0,0 +1,89 @@
+package zapLogger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// hostileInputs returns the corpus of the log injection tests, shared with the common_logger tests
+func hostileInputs(tb testing.TB) []string {
+	file, err := os.Open("../testdata/hostile.txt")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer file.Close()
+
+	var inputs []string
+	for scanner := bufio.NewScanner(file); scanner.Scan(); {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		input, err := strconv.Unquote(line)
+		if err != nil {
+			tb.Fatalf("invalid corpus line %s: %v", line, err)
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs
+}
+
+// unsafe reports whether r can forge records or terminal output
+func unsafe(r rune) bool {
+	return unicode.IsControl(r) || r == '\u2028' || r == '\u2029' || r == '\u202e'
+}
+
+// levelColor matches the colors of the levels, the only escape sequences written by the console encoder
+var levelColor = regexp.MustCompile(`\x1b\[\d+m`)
+
+// checkEncoders encodes a record made of the input with the console and the file encoders, and checks that each is
+// one line of the expected tabs, without control characters
+func checkEncoders(t *testing.T, input string) {
+	ent := zapcore.Entry{Level: zapcore.ErrorLevel, Time: time.Now(), LoggerName: input, Message: input}
+	fields := []zapcore.Field{zap.String(input, input), zap.Error(&os.PathError{Op: input, Path: input, Err: os.ErrNotExist})}
+
+	console, err := newConsoleEncoder().EncodeEntry(ent, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tabs := 4 // tabs between the time, level, logger, message and fields; the logger is omitted if it has no name
+	if input == "" {
+		tabs = 3
+	}
+	line, found := strings.CutSuffix(levelColor.ReplaceAllString(console.String(), ""), "\n")
+	if !found || strings.IndexFunc(line, func(r rune) bool { return r != '\t' && unsafe(r) }) >= 0 || strings.Count(line, "\t") != tabs {
+		t.Errorf("unsafe console output %q for input %q", console.String(), input)
+	}
+
+	file, err := newFileEncoder().EncodeEntry(ent, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, found = strings.CutSuffix(file.String(), "\n")
+	if !found || strings.IndexFunc(line, unsafe) >= 0 || !json.Valid(file.Bytes()) {
+		t.Errorf("unsafe JSON output %q for input %q", file.String(), input)
+	}
+}
+
+func TestEncodersHostileInputs(t *testing.T) {
+	for _, input := range hostileInputs(t) {
+		checkEncoders(t, input)
+	}
+}
+
+func FuzzEncodersSanitization(f *testing.F) {
+	for _, input := range hostileInputs(f) {
+		f.Add(input)
+	}
+	f.Fuzz(checkEncoders)
+}
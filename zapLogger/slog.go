@@ -0,0 +1,172 @@
+package zapLogger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slogHandler is an slog.Handler that writes to the cores of a LoggerImpl,
+// so that libraries logging with `log/slog` end up in the same sinks, with the same levels.
+type slogHandler struct {
+	core   zapcore.Core
+	name   string
+	groups []string // groups opened with WithGroup that have no attributes yet
+}
+
+// SlogHandler returns an slog.Handler that writes to the sinks of the logger and obeys its (named) level
+func (logger *LoggerImpl) SlogHandler() slog.Handler {
+	return &slogHandler{
+		core: logger.mainLogger.Core(),
+		name: logger.name,
+	}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.core.Enabled(toZapLevel(level))
+}
+
+func (h *slogHandler) Handle(_ context.Context, record slog.Record) error {
+	ent := zapcore.Entry{
+		Level:      toZapLevel(record.Level),
+		Time:       record.Time,
+		Message:    record.Message,
+		LoggerName: h.name,
+	}
+
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		ent.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+		ent.Caller.Function = frame.Function
+	}
+
+	ce := h.core.Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+
+	fields := make([]zap.Field, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		if f, ok := toZapField(attr); ok {
+			fields = append(fields, f)
+		}
+		return true
+	})
+
+	ce.Write(h.openGroups(fields)...)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]zap.Field, 0, len(attrs))
+	for _, attr := range attrs {
+		if f, ok := toZapField(attr); ok {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return h
+	}
+
+	return &slogHandler{
+		core: h.core.With(h.openGroups(fields)),
+		name: h.name,
+	}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &slogHandler{
+		core:   h.core,
+		name:   h.name,
+		groups: append(h.groups[:len(h.groups):len(h.groups)], name),
+	}
+}
+
+// openGroups prefixes the fields with the pending groups. Per the slog rules, groups without fields are omitted
+func (h *slogHandler) openGroups(fields []zap.Field) []zap.Field {
+	if len(fields) == 0 || len(h.groups) == 0 {
+		return fields
+	}
+
+	all := make([]zap.Field, 0, len(h.groups)+len(fields))
+	for _, g := range h.groups {
+		all = append(all, zap.Namespace(g))
+	}
+	return append(all, fields...)
+}
+
+// toZapField converts an slog attribute, resolving LogValuer values. Empty attributes are ignored, per the slog rules
+func toZapField(attr slog.Attr) (zap.Field, bool) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return zap.Skip(), false
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindBool:
+		return zap.Bool(attr.Key, attr.Value.Bool()), true
+	case slog.KindDuration:
+		return zap.Duration(attr.Key, attr.Value.Duration()), true
+	case slog.KindFloat64:
+		return zap.Float64(attr.Key, attr.Value.Float64()), true
+	case slog.KindInt64:
+		return zap.Int64(attr.Key, attr.Value.Int64()), true
+	case slog.KindString:
+		return zap.String(attr.Key, attr.Value.String()), true
+	case slog.KindTime:
+		return zap.Time(attr.Key, attr.Value.Time()), true
+	case slog.KindUint64:
+		return zap.Uint64(attr.Key, attr.Value.Uint64()), true
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		if len(group) == 0 {
+			return zap.Skip(), false
+		}
+		if attr.Key == "" {
+			// inline the attributes of a group without key, per the slog rules
+			return zap.Inline(slogGroup(group)), true
+		}
+		return zap.Object(attr.Key, slogGroup(group)), true
+	default:
+		if err, ok := attr.Value.Any().(error); ok {
+			return zap.NamedError(attr.Key, err), true
+		}
+		return zap.Any(attr.Key, attr.Value.Any()), true
+	}
+}
+
+// slogGroup encodes the attributes of an slog group as a zap object
+type slogGroup []slog.Attr
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, attr := range g {
+		if f, ok := toZapField(attr); ok {
+			f.AddTo(enc)
+		}
+	}
+	return nil
+}
+
+// toZapLevel maps an slog level to the closest zap level. The levels above error are mapped to error,
+// so that a handler never panics nor exits the process.
+func toZapLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case level >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case level >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	case level >= slog.LevelDebug:
+		return zapcore.DebugLevel
+	default:
+		return TraceLevel
+	}
+}
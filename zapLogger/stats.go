@@ -0,0 +1,48 @@
+package zapLogger
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// levelCounters count the records written to the outputs, by level
+type levelCounters [zapcore.FatalLevel - TraceLevel + 1]atomic.Uint64
+
+func (c *levelCounters) add(level zapcore.Level) {
+	if level >= TraceLevel && level <= zapcore.FatalLevel {
+		c[level-TraceLevel].Add(1)
+	}
+}
+
+// counts returns the counters by level name, e.g. "warn"
+func (c *levelCounters) counts() map[string]uint64 {
+	counts := make(map[string]uint64, len(c))
+	for i := range c {
+		counts[levelName(TraceLevel+zapcore.Level(i))] = c[i].Load()
+	}
+	return counts
+}
+
+// countingCore counts the records written to the wrapped core, once they have passed the levels, the sampling
+// and the collapsing of the duplicates
+type countingCore struct {
+	zapcore.Core
+	counters *levelCounters
+}
+
+func (c *countingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &countingCore{Core: c.Core.With(fields), counters: c.counters}
+}
+
+func (c *countingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *countingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.counters.add(ent.Level)
+	return c.Core.Write(ent, fields)
+}
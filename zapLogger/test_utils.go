@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 )
 
 func createTempFile() (file *os.File, tempDir string, err error) {
@@ -33,3 +34,18 @@ func GetFreePort() string {
 	address := listener.Addr().(*net.TCPAddr)
 	return strconv.Itoa(address.Port)
 }
+
+// findLogLine returns the first line of the log file that contains the given message, or "" if there is none
+func findLogLine(logFile string, msg string) string {
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.Contains(line, `"msg":"`+msg+`"`) {
+			return line
+		}
+	}
+	return ""
+}
@@ -1,10 +1,23 @@
 package zapLogger
 
-import "go.uber.org/zap"
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/vlbarou/logger/logapi"
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// toZapFields converts key-value pairs to zap fields. The pairs are expected to be normalized (see logapi.NormalizeArgs)
 func toZapFields(args ...interface{}) (fields []zap.Field) {
 
-	fields = make([]zap.Field, 0, len(args)/2)
+	// one more for the message template, see toZapMessage
+	fields = make([]zap.Field, 0, len(args)/2+1)
 
 	// Convert key-value pairs to zap fields
 	for i := 0; i < len(args)-1; i += 2 {
@@ -13,23 +26,162 @@ func toZapFields(args ...interface{}) (fields []zap.Field) {
 			continue // skip invalid key
 		}
 
-		val := args[i+1]
-
-		switch v := val.(type) {
-		case string:
-			fields = append(fields, zap.String(key, v))
-		case int:
-			fields = append(fields, zap.Int(key, v))
-		case int64:
-			fields = append(fields, zap.Int64(key, v))
-		case float64:
-			fields = append(fields, zap.Float64(key, v))
-		case bool:
-			fields = append(fields, zap.Bool(key, v))
-		default:
-			fields = append(fields, zap.Any(key, v))
-		}
+		fields = append(fields, valueToZapField(key, args[i+1]))
 	}
 
 	return
 }
+
+// valueToZapField converts a value to the zap field of its type, without reflection for the common types
+func valueToZapField(key string, val any) zap.Field {
+	switch v := val.(type) {
+	case string:
+		return zap.String(key, v)
+	case int:
+		return zap.Int(key, v)
+	case int64:
+		return zap.Int64(key, v)
+	case int32:
+		return zap.Int32(key, v)
+	case int16:
+		return zap.Int16(key, v)
+	case int8:
+		return zap.Int8(key, v)
+	case uint:
+		return zap.Uint(key, v)
+	case uint64:
+		return zap.Uint64(key, v)
+	case uint32:
+		return zap.Uint32(key, v)
+	case uint16:
+		return zap.Uint16(key, v)
+	case uint8:
+		return zap.Uint8(key, v)
+	case uintptr:
+		return zap.Uintptr(key, v)
+	case float64:
+		return zap.Float64(key, v)
+	case float32:
+		return zap.Float32(key, v)
+	case bool:
+		return zap.Bool(key, v)
+	case time.Time:
+		return zap.Time(key, v)
+	case time.Duration:
+		return zap.Duration(key, v)
+	case []byte:
+		if utf8.Valid(v) {
+			return zap.ByteString(key, v)
+		}
+		return zap.Binary(key, v) // base64
+	case zapcore.ObjectMarshaler:
+		return zap.Object(key, v)
+	case zapcore.ArrayMarshaler:
+		return zap.Array(key, v)
+	case slog.Value:
+		f, _ := toZapField(slog.Attr{Key: key, Value: v})
+		return f
+	case slog.LogValuer:
+		f, _ := toZapField(slog.Any(key, v))
+		return f
+	case error:
+		// logs the verbose form too, under key+"Verbose", if the error implements fmt.Formatter (e.g. with a stack trace)
+		return zap.NamedError(key, v)
+	case fmt.Stringer:
+		return zap.Stringer(key, v)
+	default:
+		return zap.Any(key, v)
+	}
+}
+
+// toZapMessage renders the message template with the key-value pairs (see logapi.RenderTemplate) and converts them
+// to zap fields, along with the template if the message is one
+func (logger *LoggerImpl) toZapMessage(message string, args []any) (string, []zap.Field) {
+	args = logger.normalize(args)
+	fields := toZapFields(args...)
+	rendered, template := logapi.RenderTemplate(message, logger.templateArgs(message, args))
+	if template {
+		fields = append(fields, zap.String(logapi.TemplateKey, message))
+	}
+	return rendered, fields
+}
+
+// templateArgs returns the key-value pairs to render the message with, with the sensitive values redacted:
+// the redaction of the record only finds the values matching a pattern in the rendered message, not the values of the sensitive keys
+func (logger *LoggerImpl) templateArgs(message string, args []any) []any {
+	redact := logger.root().redact // nil until Start
+	if strings.IndexByte(message, '{') < 0 || redact == nil {
+		return args
+	}
+	if r := redact.redactor.Load(); r != nil {
+		return r.Args(args)
+	}
+	return args
+}
+
+// normalize returns the key-value pairs with the malformed ones fixed (see logapi.NormalizeArgs), counting them
+func (logger *LoggerImpl) normalize(args []any) []any {
+	normalized, malformed := logapi.NormalizeArgs(args)
+	if malformed > 0 {
+		logger.root().malformedArgs.Add(uint64(malformed))
+		logapi.ReportMalformed(args, malformed)
+	}
+	return normalized
+}
+
+// sanitizingEncoder escapes the characters that could forge records or terminal output (see logapi.SanitizeText).
+// The console encoder writes the messages and the logger names as they are, so they are escaped as a whole if sanitizeEntry
+// is set; the fields, and the JSON records, only have the control characters below 0x20 escaped, so the others are escaped
+// in the output as JSON escapes, e.g. \u2028.
+type sanitizingEncoder struct {
+	zapcore.Encoder
+	sanitizeEntry bool
+}
+
+func (enc sanitizingEncoder) Clone() zapcore.Encoder {
+	return sanitizingEncoder{Encoder: enc.Encoder.Clone(), sanitizeEntry: enc.sanitizeEntry}
+}
+
+func (enc sanitizingEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	if enc.sanitizeEntry {
+		ent.Message = logapi.SanitizeText(ent.Message)
+		ent.LoggerName = logapi.SanitizeText(ent.LoggerName)
+	}
+	buf, err := enc.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	return escapeUnsafeRunes(buf), nil
+}
+
+var sanitizedPool = buffer.NewPool()
+
+// escapeUnsafeRunes returns the buffer with the unsafe runes from DEL up escaped, e.g. \u2028; it is returned as is if there are none
+func escapeUnsafeRunes(buf *buffer.Buffer) *buffer.Buffer {
+	b := buf.Bytes()
+	i := 0
+	for ; i < len(b); i++ {
+		if b[i] >= 0x7f {
+			if r, _ := utf8.DecodeRune(b[i:]); logapi.UnsafeRune(r) {
+				break
+			}
+		}
+	}
+	if i == len(b) {
+		return buf
+	}
+
+	escaped := sanitizedPool.Get()
+	escaped.Write(b[:i])
+	for i < len(b) {
+		r, size := utf8.DecodeRune(b[i:])
+		if r >= 0x7f && logapi.UnsafeRune(r) {
+			fmt.Fprintf(escaped, `\u%04x`, r)
+		} else {
+			escaped.Write(b[i : i+size])
+		}
+		i += size
+	}
+	buf.Free()
+	return escaped
+}
@@ -0,0 +1,104 @@
+package zapLogger
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/vlbarou/logger/logapi"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var commonArgs = []any{
+	"string", "value", "int", 42, "int64", int64(42), "uint", uint(42), "float64", 4.2, "bool", true,
+	"time", time.Unix(0, 0), "duration", time.Second, "error", errors.New("failure"),
+}
+
+// TestToZapFieldsAllocations checks that the common types are converted without allocating, but the fields slice
+func TestToZapFieldsAllocations(t *testing.T) {
+	if allocs := testing.AllocsPerRun(100, func() { toZapFields(commonArgs...) }); allocs > 1 {
+		t.Errorf("toZapFields allocates %v times, want 1", allocs)
+	}
+}
+
+func BenchmarkToZapFields(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		toZapFields(commonArgs...)
+	}
+}
+
+func BenchmarkToZapMessage(b *testing.B) {
+	logger := New()
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.toZapMessage("{string} took {duration}", commonArgs)
+	}
+}
+
+// newDiscardLogger returns a logger writing JSON records to io.Discard, to measure the cost of logging only
+func newDiscardLogger(tb testing.TB) *LoggerImpl {
+	logger := New().WithOutputs(OutputStderr).WithPort("")
+	if err := logger.Start(); err != nil {
+		tb.Fatal(err)
+	}
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(io.Discard), enableAll)
+	_ = logger.sinks.swap(core, nil)
+	tb.Cleanup(func() { _ = logger.Shutdown() })
+	return logger
+}
+
+// TestInfoFAllocations checks that the typed fields are passed through to zap, without allocating more than zap itself
+func TestInfoFAllocations(t *testing.T) {
+	logger := newDiscardLogger(t)
+	fields := []logapi.Field{logapi.String("path", "/users"), logapi.Int("status", 200), logapi.Duration("latency", time.Millisecond)}
+
+	zapAllocs := testing.AllocsPerRun(100, func() { logger.mainLogger.Info("request served", fields...) })
+	if allocs := testing.AllocsPerRun(100, func() { logger.InfoF("request served", fields...) }); allocs > zapAllocs {
+		t.Errorf("InfoF allocates %v times, zap %v times", allocs, zapAllocs)
+	}
+}
+
+// TestDisabledDebugFAllocations checks that the typed fields of a disabled level don't escape to the heap
+func TestDisabledDebugFAllocations(t *testing.T) {
+	logger := newDiscardLogger(t)
+	if allocs := testing.AllocsPerRun(100, func() {
+		logger.DebugF("request served", logapi.String("path", "/users"), logapi.Int("status", 200))
+	}); allocs > 0 {
+		t.Errorf("DebugF allocates %v times at a disabled level, want 0", allocs)
+	}
+}
+
+func BenchmarkInfo(b *testing.B) {
+	logger := newDiscardLogger(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.Info("request served", "path", "/users", "status", 200, "latency", time.Millisecond)
+	}
+}
+
+func BenchmarkInfoF(b *testing.B) {
+	logger := newDiscardLogger(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.InfoF("request served", logapi.String("path", "/users"), logapi.Int("status", 200), logapi.Duration("latency", time.Millisecond))
+	}
+}
+
+func BenchmarkDisabledDebug(b *testing.B) {
+	logger := newDiscardLogger(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.Debug("request served", "path", "/users", "status", 200, "latency", time.Millisecond)
+	}
+}
+
+func BenchmarkDisabledDebugF(b *testing.B) {
+	logger := newDiscardLogger(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.DebugF("request served", logapi.String("path", "/users"), logapi.Int("status", 200), logapi.Duration("latency", time.Millisecond))
+	}
+}
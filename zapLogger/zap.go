@@ -4,32 +4,55 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/vlbarou/logger/logapi"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"sync"
-)
-
-var (
-	internalLogger *zap.Logger
+	"sync/atomic"
+	"time"
 )
 
 type LoggerImpl struct {
 	mainLogger         *zap.Logger
+	internalLogger     *zap.Logger // use this logger to log in the wrapper; each instance has its own
 	maxSizeMB          int
 	maxBackups         int
 	maxAge             int
 	logServerPort      string
 	logRotationEnabled bool
 	logFile            string
+	outputs            []string      // any of OutputStdout, OutputStderr and OutputFile
 	doneCh             chan struct{} // signal that logger server terminated
 	ctx                context.Context
 	cancel             context.CancelFunc
 	atomicLevel        zap.AtomicLevel // Create an AtomicLevel to control logging level at runtime
+	levels             *levelRegistry  // runtime levels of the named loggers, falling back to atomicLevel
+	sinks              *reloadableCore // the outputs, replaced by Reconfigure
+	reconfigureMu      sync.Mutex      // serializes the reconfigurations
+	name               string          // name of the logger, as set by `Named`, e.g. "db.pool"
 	wg                 sync.WaitGroup
+	parent             *LoggerImpl   // set for loggers derived with `With`; they share the parent's sinks and lifecycle
+	exit               func(int)     // called by Fatal, once the outputs are flushed
+	development        bool          // DPanic panics in development mode
+	malformedArgs      atomic.Uint64 // see MalformedArgs
+	async              AsyncSettings
+	asyncStats         asyncCounters // see AsyncStats
+	levelCounters      levelCounters // records written by level, see the stats of the log server
+	sampling           SamplingSettings
+	sampler            *sampler // applies the sampling settings, which Reconfigure replaces
+	dedupWindow        time.Duration
+	dedup              *dedupCore // collapses the consecutive duplicates, see WithDedup
+	redaction          logapi.RedactionConfig
+	redact             *redactCore // redacts the sensitive values, see WithRedaction
+	admin              AdminSettings
+	adminAddr          net.Addr    // address of the log server, once started
+	auditLogger        *zap.Logger // writes the audit records of the log server, whatever the levels
 }
 
 func New() *LoggerImpl {
@@ -39,13 +62,14 @@ func New() *LoggerImpl {
 		maxAge:             MaxAge,
 		maxBackups:         MaxBackups,
 		maxSizeMB:          MaxSizeMB,
-		logServerPort:      LoggerServerPort,
 		logFile:            LogFile,
 		logRotationEnabled: false,
+		outputs:            []string{OutputStdout, OutputFile},
 		atomicLevel:        zap.NewAtomicLevelAt(zap.InfoLevel),
 		ctx:                ctx,
 		cancel:             cancel,
 		doneCh:             make(chan struct{}, 1), // buffered to avoid blocking
+		exit:               os.Exit,
 	}
 
 	return logger
@@ -76,29 +100,215 @@ func (logger *LoggerImpl) WithMaxAge(m int) *LoggerImpl {
 	return logger
 }
 
+// WithLevel sets the initial global level
+func (logger *LoggerImpl) WithLevel(level zapcore.Level) *LoggerImpl {
+	logger.atomicLevel.SetLevel(level)
+	return logger
+}
+
+// WithOutputs sets the outputs to write to, any of OutputStdout, OutputStderr and OutputFile
+func (logger *LoggerImpl) WithOutputs(outputs ...string) *LoggerImpl {
+	logger.outputs = outputs
+	return logger
+}
+
+// WithExitFunc sets the function called by Fatal, once the outputs are flushed (os.Exit by default)
+func (logger *LoggerImpl) WithExitFunc(exit func(int)) *LoggerImpl {
+	logger.exit = exit
+	return logger
+}
+
+// WithDevelopment enables the development mode, in which DPanic panics
+func (logger *LoggerImpl) WithDevelopment(d bool) *LoggerImpl {
+	logger.development = d
+	return logger
+}
+
+// WithSettings applies all the settings at once
+func (logger *LoggerImpl) WithSettings(settings Settings) *LoggerImpl {
+	return logger.
+		WithLogfile(settings.LogFile).
+		WithLogRotation(settings.LogRotation).
+		WithMaxSizeMB(settings.MaxSizeMB).
+		WithMaxBackups(settings.MaxBackups).
+		WithMaxAge(settings.MaxAge).
+		WithLevel(settings.Level).
+		WithOutputs(settings.Outputs...).
+		WithAsync(settings.Async).
+		WithSampling(settings.Sampling).
+		WithDedup(settings.DedupWindow).
+		WithRedaction(settings.Redaction)
+}
+
+// WithAsync enables the asynchronous writes, if the queue size is not 0
+func (logger *LoggerImpl) WithAsync(async AsyncSettings) *LoggerImpl {
+	logger.async = async
+	return logger
+}
+
+// WithSampling limits the records of the repeated messages
+func (logger *LoggerImpl) WithSampling(sampling SamplingSettings) *LoggerImpl {
+	logger.sampling = sampling
+	return logger
+}
+
+// WithDedup collapses the consecutive records of the same level, logger, message and fields logged within the window:
+// only the first one is written, followed by a summary of its repetitions (`last message repeated N times`)
+// once the window expires, or earlier if a different record is logged. A zero window disables the collapsing.
+func (logger *LoggerImpl) WithDedup(window time.Duration) *LoggerImpl {
+	logger.dedupWindow = window
+	return logger
+}
+
+// WithRedaction redacts the sensitive values of the records, selected by their keys and by patterns, at any depth
+func (logger *LoggerImpl) WithRedaction(redaction logapi.RedactionConfig) *LoggerImpl {
+	logger.redaction = redaction
+	return logger
+}
+
+// WithPort enables the log server on the given port, e.g. LoggerServerPort; the server is disabled by default.
+// With port "0", a free port is chosen, see AdminAddr.
 func (logger *LoggerImpl) WithPort(port string) *LoggerImpl {
 	logger.logServerPort = port
 	return logger
 }
 
-// isIgnorableSyncError safely ignores the error thrown when trying to sync to `os.Stdout`
+// isIgnorableSyncError safely ignores the error thrown when trying to sync to `os.Stdout` or `os.Stderr`
 // In particular, Zap's Sync() flushes buffered logs to the underlying writer.
 // When the writer happens to be `os.Stdout` or `os.Stderr`, Zap tries to fsync() (flush to disk).
 //
-// But `/dev/stdout` is not a real file on disk. It is a pseudo-device, and fsync() on it is not supported.
-// Hence, in case of trying to sync with `os.Stdout` the error says “You can't fsync() a device like /dev/stdout.”
-// and we can safely ignore it
+// But `/dev/stdout` and `/dev/stderr` are not real files on disk. They are pseudo-devices (e.g. a pipe or a terminal),
+// and fsync() on them is not supported. Hence, in case of trying to sync with them the error says
+// “You can't fsync() a device like /dev/stdout.” and we can safely ignore it
 var isIgnorableSyncError = func(err error) bool {
 	var pathErr *os.PathError
-	return errors.As(err, &pathErr) && pathErr.Path == "/dev/stdout"
+	return errors.As(err, &pathErr) && (pathErr.Path == "/dev/stdout" || pathErr.Path == "/dev/stderr")
+}
+
+// root returns the logger that owns the sinks, the atomic level and the log server
+func (logger *LoggerImpl) root() *LoggerImpl {
+	if logger.parent != nil {
+		return logger.parent
+	}
+	return logger
+}
+
+// With returns a child logger that adds the given key-value pairs to every record.
+// The child writes to the same sinks, obeys the same atomic level and is shut down along with its parent.
+func (logger *LoggerImpl) With(args ...any) logapi.Logger {
+	return &LoggerImpl{
+		mainLogger: logger.mainLogger.With(toZapFields(logger.normalize(args)...)...),
+		name:       logger.name,
+		parent:     logger.root(),
+	}
+}
+
+// Named returns a child logger whose name is the dot-separated join of the parent name and the given name.
+// The level of a named logger can be changed independently (see SetLevel); until then it inherits the level of its parent.
+func (logger *LoggerImpl) Named(name string) logapi.Logger {
+	l := logger.mainLogger.Named(name)
+	return &LoggerImpl{
+		mainLogger: l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return renameLevelCore(core, l.Name())
+		})),
+		name:   l.Name(),
+		parent: logger.root(),
+	}
+}
+
+// Reconfigure atomically replaces the outputs, the log file and its rotation, the sampling, the collapsing of the duplicates
+// and the redaction, and sets the global level, while the logger keeps being used by other goroutines. Every record is written exactly once, to either the old or the new outputs.
+// If the new outputs can't be created, the logger keeps its current configuration.
+func (logger *LoggerImpl) Reconfigure(settings Settings) error {
+	root := logger.root()
+	root.reconfigureMu.Lock()
+	defer root.reconfigureMu.Unlock()
+
+	redactor, err := logapi.NewRedactor(settings.Redaction)
+	if err != nil {
+		return fmt.Errorf("failed to reconfigure logger: invalid redaction: %w", err)
+	}
+
+	previous := root.settings()
+	root.WithSettings(settings)
+
+	sinks, closers, err := root.createSinks()
+	if err != nil {
+		root.WithSettings(previous)
+		return fmt.Errorf("failed to reconfigure logger: %w", err)
+	}
+
+	// the repetitions of the last record are written to the replaced outputs
+	root.dedup.setWindow(root.dedupWindow)
+	root.redact.redactor.Store(redactor)
+	err = root.sinks.swap(sinks, closers)
+	root.sampler.configure(root.sampling)
+	root.internalLogger.Info("Logger reconfigured", zap.String("log_file", settings.LogFile), zap.Strings("outputs", settings.Outputs))
+
+	if err != nil {
+		return fmt.Errorf("failed to close the replaced outputs: %w", err)
+	}
+	return nil
+}
+
+// settings returns the current settings
+func (logger *LoggerImpl) settings() Settings {
+	return Settings{
+		LogFile:     logger.logFile,
+		LogRotation: logger.logRotationEnabled,
+		MaxSizeMB:   logger.maxSizeMB,
+		MaxBackups:  logger.maxBackups,
+		MaxAge:      logger.maxAge,
+		Level:       logger.atomicLevel.Level(),
+		Outputs:     logger.outputs,
+		Async:       logger.async,
+		Sampling:    logger.sampling,
+		DedupWindow: logger.dedupWindow,
+		Redaction:   logger.redaction,
+	}
+}
+
+// WithCallerSkip returns a child logger that skips n more stack frames to report the caller,
+// for callers that wrap the logger in their own functions
+func (logger *LoggerImpl) WithCallerSkip(n int) logapi.Logger {
+	return &LoggerImpl{
+		mainLogger: logger.mainLogger.WithOptions(zap.AddCallerSkip(n)),
+		name:       logger.name,
+		parent:     logger.root(),
+	}
+}
+
+// SetLevel changes at runtime the level of the named logger and of its descendants that have no level of their own.
+// The empty name refers to the global level.
+func (logger *LoggerImpl) SetLevel(name string, level zapcore.Level) {
+	logger.root().levels.setLevel(name, level)
+}
+
+// ResetLevel removes the level of the named logger, which then inherits the level of its parent
+func (logger *LoggerImpl) ResetLevel(name string) {
+	logger.root().levels.resetLevel(name)
+}
+
+// Level returns the effective level of the named logger
+func (logger *LoggerImpl) Level(name string) zapcore.Level {
+	return logger.root().levels.level(name)
 }
 
 func (logger *LoggerImpl) Sync() {
+	if logger.parent != nil {
+		logger.parent.Sync()
+		return
+	}
+
 	logger.mainLogger.Sync()
-	internalLogger.Sync()
+	logger.internalLogger.Sync()
 }
 
 func (logger *LoggerImpl) Shutdown() error {
+	if logger.parent != nil {
+		return logger.parent.Shutdown()
+	}
+
 	var err1, err2 error
 
 	// Trigger cancellation to start shutdown process
@@ -106,20 +316,36 @@ func (logger *LoggerImpl) Shutdown() error {
 
 	// Wait until all goroutines started by Start() have finished
 	<-logger.doneCh
+	if logger.mainLogger == nil {
+		return nil // Start failed to create the outputs
+	}
+
+	// write the summaries of the records suppressed by the sampling
+	logger.sampler.close()
 
 	// close internal logger (just flush to disk in-flight data)
-	if err := internalLogger.Sync(); err != nil && !isIgnorableSyncError(err) {
+	if err := logger.internalLogger.Sync(); err != nil && !isIgnorableSyncError(err) {
 		err1 = err
 	}
 	// close main logger (just flush to disk in-flight data)
 	if err := logger.mainLogger.Sync(); err != nil && !isIgnorableSyncError(err) {
 		err2 = err
 	}
+	// stop the asynchronous writes, once the queued records are written
+	for _, closer := range logger.sinks.closers() {
+		if pipeline, ok := closer.(*asyncPipeline); ok {
+			err2 = errors.Join(err2, pipeline.Close())
+		}
+	}
 
 	return errors.Join(err1, err2)
 }
 
 func (logger *LoggerImpl) IsShutdown() bool {
+	if logger.parent != nil {
+		return logger.parent.IsShutdown()
+	}
+
 	select {
 	case <-logger.doneCh:
 		return true
@@ -166,38 +392,37 @@ func (logger *LoggerImpl) IsShutdown() bool {
 //	return logger
 //}
 
-func (logger *LoggerImpl) Start() *LoggerImpl {
-	logger.createLogger()
-
-	mux := http.NewServeMux()
-	mux.HandleFunc(LogServerURI, logger.logLevelHandler)
-
-	server := &http.Server{
-		Addr:    ":" + logger.logServerPort,
-		Handler: mux,
+// Start creates the outputs and starts the log server if it is enabled (see WithPort). If the outputs can't be created,
+// e.g. because the log file can't be opened, or the redaction is invalid, the error is returned and the logger can't be used.
+// If the server can't be started, e.g. because its port is in use, the error is returned; the logger still writes
+// to its outputs and has to be shut down.
+func (logger *LoggerImpl) Start() error {
+	if err := logger.createLogger(); err != nil {
+		close(logger.doneCh) // nothing to shut down
+		return err
 	}
 
-	internalLogger.Info(fmt.Sprintf("Starting log server on :%s", logger.logServerPort))
-
-	logger.wg.Add(1)
-	go func() {
-		defer logger.wg.Done()
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Error("HTTP server failed", zap.Error(err))
-		}
-	}()
+	var server *http.Server
+	var err error
+	if logger.logServerPort != "" {
+		server, err = logger.startServer()
+	}
 
 	logger.wg.Add(1)
 	go func() {
 		defer logger.wg.Done()
 		<-logger.ctx.Done() // When context is canceled this unblocks and the shutdown process continues
 
-		internalLogger.Info("Shutting down log server...")
+		if server == nil {
+			return
+		}
+
+		logger.internalLogger.Info("Shutting down log server...")
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), GracefulShutdownTimeout)
 		defer cancel()
 
 		if err := server.Shutdown(shutdownCtx); err != nil {
-			logger.Error("Server shutdown failed", zap.Error(err))
+			logger.internalLogger.Error("Server shutdown failed", zap.Error(err))
 		}
 	}()
 
@@ -207,88 +432,345 @@ func (logger *LoggerImpl) Start() *LoggerImpl {
 		close(logger.doneCh)
 	}()
 
-	return logger
+	return err
+}
+
+// startServer listens on the address of the log server and serves the requests in the background
+func (logger *LoggerImpl) startServer() (*http.Server, error) {
+	tlsConfig, err := logger.admin.tlsConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS settings of the log server: %w", err)
+	}
+	if !logger.admin.loopback() && !logger.admin.authenticated() && logger.admin.ClientCAFile == "" {
+		logger.internalLogger.Warn("The log server accepts unauthenticated requests from the network", zap.String("host", logger.admin.host()))
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(logger.admin.host(), logger.logServerPort))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the log server: %w", err)
+	}
+	logger.adminAddr = listener.Addr()
+	server := &http.Server{Handler: logger.AdminHandler(), TLSConfig: tlsConfig}
+
+	logger.internalLogger.Sugar().Infof("Starting log server on %s", logger.adminAddr)
+
+	logger.wg.Add(1)
+	go func() {
+		defer logger.wg.Done()
+		var err error
+		if tlsConfig != nil {
+			err = server.ServeTLS(listener, "", "") // the certificates are in the TLS config
+		} else {
+			err = server.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
+			logger.internalLogger.Error("HTTP server failed", zap.Error(err))
+		}
+	}()
+	return server, nil
+}
+
+// AdminAddr returns the address the log server listens on, e.g. to find the port chosen for port "0",
+// or nil if the server is not started
+func (logger *LoggerImpl) AdminAddr() net.Addr {
+	return logger.root().adminAddr
+}
+
+// AdminHandler returns the handler of the log server: the JSON API under AdminAPIPrefix and the former LogServerURI
+// endpoint. It authenticates the requests (see AdminSettings).
+func (logger *LoggerImpl) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(LogServerURI, logger.logLevelHandler)
+	logger.registerAPI(mux)
+	return logger.authorize(mux)
 }
 
+// MountAdmin registers the handlers of the log server on an existing mux, instead of starting a dedicated server
+// with WithPort. The mux may be served on any interface, so the requests must be authenticated with the BearerToken
+// or the HMACKey of WithAdmin; the Host of the settings doesn't apply, and TLS is up to the server of the mux.
+// The handlers use fixed patterns, so mounting a second logger on the same mux panics, like http.ServeMux.Handle.
+func (logger *LoggerImpl) MountAdmin(mux *http.ServeMux) error {
+	if !logger.root().admin.authenticated() {
+		return errors.New("mounting the log server requires a BearerToken or an HMACKey")
+	}
+	handler := logger.AdminHandler()
+	mux.Handle(LogServerURI, handler)
+	mux.Handle(AdminAPIPrefix+"/", handler)
+	return nil
+}
+
+// logLevelHandler is the former endpoint of the log server, which sets a level with the query parameters
+// "level" and "logger", on POST or PUT, so that a link or an image can't change it. It is kept for the existing clients;
+// see the JSON API under AdminAPIPrefix.
 func (logger *LoggerImpl) logLevelHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		w.Header().Set("Allow", "POST, PUT")
+		http.Error(w, "method not allowed, use POST or PUT", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.URL.Query().Get("logger") // empty for the global level
 	level := r.URL.Query().Get("level")
 	if level == "" {
 		http.Error(w, "level is required", http.StatusBadRequest)
 		return
 	}
 
-	var newLevel zapcore.Level
-	if err := newLevel.UnmarshalText([]byte(level)); err != nil {
+	newLevel, err := parseLevel(level)
+	if err != nil {
 		http.Error(w, fmt.Sprintf("invalid level: %v", err), http.StatusBadRequest)
 		return
 	}
 
-	logger.atomicLevel.SetLevel(newLevel)
-	internalLogger.Error("Log level changed", zap.String("new_level", newLevel.String()))
-	fmt.Fprintf(w, "Log level set to %s\n", newLevel.String())
+	oldLevel := logger.Level(name)
+	logger.SetLevel(name, newLevel)
+	logger.audit(r, "Log level changed", zap.String("target", name), zap.String("old_level", levelName(oldLevel)), zap.String("new_level", levelName(newLevel)))
+	if name == "" {
+		fmt.Fprintf(w, "Log level set to %s\n", newLevel.String())
+		return
+	}
+
+	fmt.Fprintf(w, "Log level of %s set to %s\n", name, newLevel.String())
+}
+
+func (logger *LoggerImpl) Trace(message string, args ...any) {
+	if ce := logger.mainLogger.Check(TraceLevel, message); ce != nil {
+		var fields []zap.Field
+		ce.Message, fields = logger.toZapMessage(message, args)
+		ce.Write(fields...)
+	}
 }
 
 func (logger *LoggerImpl) Info(message string, args ...any) {
-	fields := toZapFields(args...)
-	logger.mainLogger.Info(message, fields...)
+	if ce := logger.mainLogger.Check(zapcore.InfoLevel, message); ce != nil {
+		var fields []zap.Field
+		ce.Message, fields = logger.toZapMessage(message, args)
+		ce.Write(fields...)
+	}
 }
 
 func (logger *LoggerImpl) Debug(message string, args ...any) {
-	fields := toZapFields(args...)
-	logger.mainLogger.Debug(message, fields...)
+	if ce := logger.mainLogger.Check(zapcore.DebugLevel, message); ce != nil {
+		var fields []zap.Field
+		ce.Message, fields = logger.toZapMessage(message, args)
+		ce.Write(fields...)
+	}
 }
 
 func (logger *LoggerImpl) Error(message string, args ...any) {
-	fields := toZapFields(args...)
-	logger.mainLogger.Error(message, fields...)
+	if ce := logger.mainLogger.Check(zapcore.ErrorLevel, message); ce != nil {
+		var fields []zap.Field
+		ce.Message, fields = logger.toZapMessage(message, args)
+		ce.Write(fields...)
+	}
 }
 
 func (logger *LoggerImpl) Warn(message string, args ...any) {
-	fields := toZapFields(args...)
-	logger.mainLogger.Warn(message, fields...)
+	if ce := logger.mainLogger.Check(zapcore.WarnLevel, message); ce != nil {
+		var fields []zap.Field
+		ce.Message, fields = logger.toZapMessage(message, args)
+		ce.Write(fields...)
+	}
 }
 
-func (logger *LoggerImpl) createLogger() {
-	var file zapcore.WriteSyncer
-	stdout := zapcore.AddSync(os.Stdout)
+func (logger *LoggerImpl) Tracef(format string, args ...any) {
+	if ce := logger.mainLogger.Check(TraceLevel, format); ce != nil {
+		writef(ce, format, args)
+	}
+}
 
-	if logger.logRotationEnabled {
-		/*
-			lumberjack.Logger doesn't have a built-in Shutdown or Close method.
-			So once started, it's a zombie goroutine unless the process exits.
-		*/
-		file = zapcore.AddSync(&lumberjack.Logger{
-			Filename:   logger.logFile,
-			MaxSize:    logger.maxSizeMB,  // Maximum size (in MB) of a single log file before it gets rotated (e.g., 10MB)
-			MaxBackups: logger.maxBackups, // Number of old log files to keep (e.g., 3 old logs)
-			MaxAge:     logger.maxAge,     // Maximum age (in days) to retain old log files (e.g., 7 days)
-			Compress:   Compress,          // ✅ compress rotated files (.gz), hardcoded in the format "<filename>-<timestamp>.gz"
-		})
-	} else {
-		fileHandle, err := OpenOrCreateFile(logger.logFile)
-		if err != nil {
-			panic(fmt.Sprintf("failed to open log file: %v", err))
-		}
-		file = zapcore.AddSync(fileHandle)
+func (logger *LoggerImpl) Debugf(format string, args ...any) {
+	if ce := logger.mainLogger.Check(zapcore.DebugLevel, format); ce != nil {
+		writef(ce, format, args)
+	}
+}
+
+func (logger *LoggerImpl) Infof(format string, args ...any) {
+	if ce := logger.mainLogger.Check(zapcore.InfoLevel, format); ce != nil {
+		writef(ce, format, args)
 	}
+}
 
-	// Initialize AtomicLevel globally so it can be updated
-	logger.atomicLevel = zap.NewAtomicLevelAt(zap.InfoLevel)
+func (logger *LoggerImpl) Warnf(format string, args ...any) {
+	if ce := logger.mainLogger.Check(zapcore.WarnLevel, format); ce != nil {
+		writef(ce, format, args)
+	}
+}
 
-	productionCfg := zap.NewProductionEncoderConfig()
-	productionCfg.TimeKey = TimeKey
-	productionCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+func (logger *LoggerImpl) Errorf(format string, args ...any) {
+	if ce := logger.mainLogger.Check(zapcore.ErrorLevel, format); ce != nil {
+		writef(ce, format, args)
+	}
+}
 
-	developmentCfg := zap.NewDevelopmentEncoderConfig()
-	developmentCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+func (logger *LoggerImpl) TraceFn(fn logapi.MessageFunc) {
+	if ce := logger.mainLogger.Check(TraceLevel, ""); ce != nil {
+		logger.writeFn(ce, fn)
+	}
+}
+
+func (logger *LoggerImpl) DebugFn(fn logapi.MessageFunc) {
+	if ce := logger.mainLogger.Check(zapcore.DebugLevel, ""); ce != nil {
+		logger.writeFn(ce, fn)
+	}
+}
 
-	consoleEncoder := zapcore.NewConsoleEncoder(developmentCfg)
-	fileEncoder := zapcore.NewJSONEncoder(productionCfg)
+func (logger *LoggerImpl) InfoFn(fn logapi.MessageFunc) {
+	if ce := logger.mainLogger.Check(zapcore.InfoLevel, ""); ce != nil {
+		logger.writeFn(ce, fn)
+	}
+}
 
-	core := zapcore.NewTee(
-		zapcore.NewCore(consoleEncoder, stdout, logger.atomicLevel),
-		zapcore.NewCore(fileEncoder, file, logger.atomicLevel),
-	)
+func (logger *LoggerImpl) WarnFn(fn logapi.MessageFunc) {
+	if ce := logger.mainLogger.Check(zapcore.WarnLevel, ""); ce != nil {
+		logger.writeFn(ce, fn)
+	}
+}
+
+func (logger *LoggerImpl) ErrorFn(fn logapi.MessageFunc) {
+	if ce := logger.mainLogger.Check(zapcore.ErrorLevel, ""); ce != nil {
+		logger.writeFn(ce, fn)
+	}
+}
+
+// The typed variants check the level before touching the fields, which are then copied to a pooled buffer (see writeTyped),
+// so that the variadic slice of the callers doesn't escape: a disabled level costs no allocation when the logger is called
+// directly, rather than through the Logger interface.
+
+func (logger *LoggerImpl) TraceF(message string, fields ...logapi.Field) {
+	if ce := logger.mainLogger.Check(TraceLevel, message); ce != nil {
+		writeTyped(ce, fields)
+	}
+}
+
+func (logger *LoggerImpl) DebugF(message string, fields ...logapi.Field) {
+	if ce := logger.mainLogger.Check(zapcore.DebugLevel, message); ce != nil {
+		writeTyped(ce, fields)
+	}
+}
+
+func (logger *LoggerImpl) InfoF(message string, fields ...logapi.Field) {
+	if ce := logger.mainLogger.Check(zapcore.InfoLevel, message); ce != nil {
+		writeTyped(ce, fields)
+	}
+}
+
+func (logger *LoggerImpl) WarnF(message string, fields ...logapi.Field) {
+	if ce := logger.mainLogger.Check(zapcore.WarnLevel, message); ce != nil {
+		writeTyped(ce, fields)
+	}
+}
+
+func (logger *LoggerImpl) ErrorF(message string, fields ...logapi.Field) {
+	if ce := logger.mainLogger.Check(zapcore.ErrorLevel, message); ce != nil {
+		writeTyped(ce, fields)
+	}
+}
+
+// typedFields are the buffers of writeTyped
+var typedFields = sync.Pool{New: func() any { return new([]zapcore.Field) }}
+
+// writeTyped writes the checked entry with a copy of the fields, which the cores don't keep once written
+func writeTyped(ce *zapcore.CheckedEntry, fields []logapi.Field) {
+	buf := typedFields.Get().(*[]zapcore.Field)
+	*buf = append((*buf)[:0], fields...)
+	ce.Write(*buf...)
+	clear(*buf) // don't keep the values alive
+	typedFields.Put(buf)
+}
+
+// AsyncStats returns the counters of the asynchronous writes (see WithAsync)
+func (logger *LoggerImpl) AsyncStats() AsyncStats {
+	stats := &logger.root().asyncStats
+	return AsyncStats{
+		Enqueued: stats.enqueued.Load(),
+		Dropped:  stats.dropped.Load(),
+		Written:  stats.written.Load(),
+	}
+}
+
+// MalformedArgs returns the number of malformed key-value arguments given to the logger and its children (see logapi.NormalizeArgs)
+func (logger *LoggerImpl) MalformedArgs() uint64 {
+	return logger.root().malformedArgs.Load()
+}
+
+// Enabled reports whether a record of the given level would be logged, according to the level of the named logger
+func (logger *LoggerImpl) Enabled(level logapi.Level) bool {
+	return logger.mainLogger.Core().Enabled(zapcore.Level(level))
+}
+
+// writef formats the message of a checked entry, once the level is known to be enabled.
+// The entry is checked with the format as message, so that the cores that inspect it (e.g. samplers) group the records by format.
+func writef(ce *zapcore.CheckedEntry, format string, args []any) {
+	ce.Message = fmt.Sprintf(format, args...)
+	ce.Write()
+}
+
+// writeFn builds the message and the fields of a checked entry, once the level is known to be enabled
+func (logger *LoggerImpl) writeFn(ce *zapcore.CheckedEntry, fn logapi.MessageFunc) {
+	message, args := fn()
+	message, fields := logger.toZapMessage(message, args)
+	ce.Message = message
+	ce.Write(fields...)
+}
+
+// DPanic logs at DPanicLevel, and panics in development mode
+func (logger *LoggerImpl) DPanic(message string, args ...any) {
+	message, fields := logger.toZapMessage(message, args)
+	logger.mainLogger.DPanic(message, fields...)
+}
+
+// Panic logs at PanicLevel, then panics
+func (logger *LoggerImpl) Panic(message string, args ...any) {
+	message, fields := logger.toZapMessage(message, args)
+	logger.mainLogger.Panic(message, fields...)
+}
+
+// Fatal logs at FatalLevel, flushes the outputs, then calls the exit function (see WithExitFunc)
+func (logger *LoggerImpl) Fatal(message string, args ...any) {
+	message, fields := logger.toZapMessage(message, args)
+	logger.mainLogger.Fatal(message, fields...)
+}
+
+// exitHook flushes the outputs and exits after a fatal record
+type exitHook struct {
+	logger *LoggerImpl
+}
+
+// OnWrite implements zapcore.CheckWriteHook: it runs after a fatal record is written
+func (h exitHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {
+	h.logger.Sync()
+	h.logger.exit(1)
+}
+
+func (logger *LoggerImpl) createLogger() error {
+	redactor, err := logapi.NewRedactor(logger.redaction)
+	if err != nil {
+		return fmt.Errorf("invalid redaction: %w", err)
+	}
+
+	sinks, closers, err := logger.createSinks()
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	logger.sinks = newReloadableCore(sinks, closers)
+
+	// levels are initialized here, since the atomic level may have been set with `WithLevel`
+	logger.levels = newLevelRegistry(logger.atomicLevel)
+	logger.redact = newRedactCore(&countingCore{Core: logger.sinks, counters: &logger.levelCounters}, redactor)
+
+	// the summaries of the sampling quote the messages, so they are redacted and counted too
+	logger.sampler = newSampler(logger.redact)
+	logger.sampler.configure(logger.sampling)
+
+	logger.dedup = newDedupCore(logger.redact, logger.dedupWindow)
+
+	// the levels are checked once, by namedLevelCore, so that named loggers can be more verbose than the global level,
+	// then the records are sampled, the consecutive duplicates collapsed, and the sensitive values redacted
+	core := &namedLevelCore{
+		Core:   &samplingCore{Core: logger.dedup, sampler: logger.sampler},
+		levels: logger.levels,
+	}
 
 	/*
 		Since we use wrapper, we don't want just the "AddCaller". This would invoke the IMMEDIATE caller, which is the
@@ -296,16 +778,107 @@ func (logger *LoggerImpl) createLogger() {
 		To fix this, you need to adjust the stack frame depth using zap.AddCallerSkip(n), where n is how many stack frames to skip beyond the default.
 		Hence, zap.AddCallerSkip(N) skips N frames in the call stack to find the "real" caller.
 
-		If you have multiple layers of wrapper functions (e.g., structured logger → generic logger → zap.Logger), you may need to bump AddCallerSkip(2) or more.
+		The main logger skips 1 frame, i.e. the method of LoggerImpl (e.g. Info), which is right when the application calls
+		the methods directly, or through a child logger. Callers that wrap the logger again (e.g. the `common_logger` package
+		functions) add their own frames with WithCallerSkip.
 
 		With only 1 frame skipped, zap is more conservative, so even if the call stack is "flattened" or modified during shutdown, it can still locate a valid caller frame.
 		Instead, if set to 2 it logs: "Logger.check error: failed to get caller"
 	*/
 
-	internalLogger = zap.New(core, zap.AddCaller())                          // use this logger to log in the wrapper
-	logger.mainLogger = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(2)) // use this logger for your main app
+	options := []zap.Option{zap.AddCaller(), zap.WithFatalHook(exitHook{logger: logger})}
+	if logger.development {
+		options = append(options, zap.Development())
+	}
+
+	// the audit records are only redacted
+	logger.auditLogger = zap.New(logger.redact).Named("audit")
+
+	logger.internalLogger = zap.New(core, options...)                           // use this logger to log in the wrapper
+	logger.mainLogger = zap.New(core, append(options, zap.AddCallerSkip(1))...) // use this logger for your main app
+
+	return nil
+}
+
+// createSinks returns a core that writes to every output, along with the files to close once it is replaced
+func (logger *LoggerImpl) createSinks() (zapcore.Core, []io.Closer, error) {
+	consoleEncoder := newConsoleEncoder()
+	fileEncoder := newFileEncoder()
+
+	var closers []io.Closer
+	newCore := func(enc zapcore.Encoder, out zapcore.WriteSyncer) zapcore.Core {
+		return zapcore.NewCore(enc, out, enableAll)
+	}
+	if logger.async.QueueSize > 0 {
+		// closed first, so that the queued records are written before the files are closed
+		pipeline := newAsyncPipeline(logger.async, &logger.asyncStats)
+		closers = append(closers, pipeline)
+		newCore = pipeline.core
+	}
+
+	cores := make([]zapcore.Core, 0, len(logger.outputs))
+	for _, output := range logger.outputs {
+		switch output {
+		case OutputStdout:
+			cores = append(cores, newCore(consoleEncoder, zapcore.AddSync(os.Stdout)))
+		case OutputStderr:
+			cores = append(cores, newCore(consoleEncoder, zapcore.AddSync(os.Stderr)))
+		case OutputFile:
+			file, err := logger.createFileWriter()
+			if err != nil {
+				closeAll(closers)
+				return nil, nil, err
+			}
+			closers = append(closers, file)
+			cores = append(cores, newCore(fileEncoder, zapcore.AddSync(file)))
+		}
+	}
+
+	return zapcore.NewTee(cores...), closers, nil
+}
+
+// newConsoleEncoder returns the encoder of the standard outputs, in color, with the control characters escaped (see sanitizingEncoder)
+func newConsoleEncoder() zapcore.Encoder {
+	developmentCfg := zap.NewDevelopmentEncoderConfig()
+	developmentCfg.EncodeLevel = capitalColorLevelEncoder
+
+	return sanitizingEncoder{Encoder: zapcore.NewConsoleEncoder(developmentCfg), sanitizeEntry: true}
+}
+
+// newFileEncoder returns the JSON encoder of the log file, with the control characters escaped (see sanitizingEncoder)
+func newFileEncoder() zapcore.Encoder {
+	productionCfg := zap.NewProductionEncoderConfig()
+	productionCfg.TimeKey = TimeKey
+	productionCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	productionCfg.EncodeLevel = lowercaseLevelEncoder
+
+	return sanitizingEncoder{Encoder: zapcore.NewJSONEncoder(productionCfg)}
+}
+
+// closeAll closes the outputs created so far, when the next one can't be created
+func closeAll(closers []io.Closer) {
+	for _, closer := range closers {
+		_ = closer.Close()
+	}
+}
+
+// createFileWriter returns the writer of the log file, rotated by lumberjack if log rotation is enabled
+func (logger *LoggerImpl) createFileWriter() (io.WriteCloser, error) {
+	if logger.logRotationEnabled {
+		/*
+			lumberjack.Logger doesn't have a built-in Shutdown method: Close only closes the current file.
+			So once started, its compression goroutine is a zombie unless the process exits.
+		*/
+		return &lumberjack.Logger{
+			Filename:   logger.logFile,
+			MaxSize:    logger.maxSizeMB,  // Maximum size (in MB) of a single log file before it gets rotated (e.g., 10MB)
+			MaxBackups: logger.maxBackups, // Number of old log files to keep (e.g., 3 old logs)
+			MaxAge:     logger.maxAge,     // Maximum age (in days) to retain old log files (e.g., 7 days)
+			Compress:   Compress,          // ✅ compress rotated files (.gz), hardcoded in the format "<filename>-<timestamp>.gz"
+		}, nil
+	}
 
-	return
+	return OpenOrCreateFile(logger.logFile)
 }
 
 // OpenOrCreateFile ensures the directory exists, and opens the file for appending.
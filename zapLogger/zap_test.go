@@ -1,10 +1,20 @@
 package zapLogger
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"github.com/vlbarou/logger/logapi"
+	"go.uber.org/zap/zapcore"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -26,7 +36,8 @@ func (suite *ZapLogTestSuite) TestLoggerServerTerminates() {
 	var err error
 	suite.tempLogFile, suite.tempDir, err = createTempFile()
 
-	suite.logger = New().WithLogfile(suite.tempLogFile.Name()).Start()
+	suite.logger = New().WithLogfile(suite.tempLogFile.Name()).WithPort("0")
+	assert.Nil(suite.T(), suite.logger.Start())
 
 	// Allow some time for the server to start
 	time.Sleep(100 * time.Millisecond)
@@ -50,7 +61,8 @@ func (suite *ZapLogTestSuite) TestIsShutDown() {
 	var err error
 	suite.tempLogFile, suite.tempDir, err = createTempFile()
 
-	suite.logger = New().WithLogfile(suite.tempLogFile.Name()).Start()
+	suite.logger = New().WithLogfile(suite.tempLogFile.Name()).WithPort("0")
+	assert.Nil(suite.T(), suite.logger.Start())
 
 	// Allow some time for the server to start
 	time.Sleep(100 * time.Millisecond)
@@ -62,6 +74,12 @@ func (suite *ZapLogTestSuite) TestIsShutDown() {
 	assert.True(suite.T(), suite.logger.IsShutdown())
 }
 
+func TestIsIgnorableSyncError(t *testing.T) {
+	assert.True(t, isIgnorableSyncError(&os.PathError{Op: "sync", Path: "/dev/stdout", Err: os.ErrInvalid}))
+	assert.True(t, isIgnorableSyncError(fmt.Errorf("flush: %w", &os.PathError{Op: "sync", Path: "/dev/stderr", Err: os.ErrInvalid})))
+	assert.False(t, isIgnorableSyncError(&os.PathError{Op: "sync", Path: "/var/log/app.log", Err: os.ErrInvalid}))
+}
+
 func (suite *ZapLogTestSuite) TestStartWithConfig() {
 
 	var err error
@@ -75,8 +93,8 @@ func (suite *ZapLogTestSuite) TestStartWithConfig() {
 		WithPort(port).
 		WithMaxAge(10).
 		WithMaxBackups(20).
-		WithMaxSizeMB(30).
-		Start()
+		WithMaxSizeMB(30)
+	assert.Nil(suite.T(), suite.logger.Start())
 
 	assert.Nil(suite.T(), err)
 	assert.NotNil(suite.T(), suite.logger)
@@ -95,8 +113,8 @@ func (suite *ZapLogTestSuite) TestLogRotation() {
 	suite.logger = New().
 		WithLogfile(suite.tempLogFile.Name()).
 		WithLogRotation(true).
-		WithMaxSizeMB(1).
-		Start()
+		WithMaxSizeMB(1)
+	assert.Nil(suite.T(), suite.logger.Start())
 
 	// Write logs repeatedly to trigger logRotationEnabled
 	largeMsg := strings.Repeat("A", 100*1024) // 100 KB per log line
@@ -119,6 +137,528 @@ func (suite *ZapLogTestSuite) TestLogRotation() {
 	assert.Equal(suite.T(), 3, len(files))
 }
 
+func (suite *ZapLogTestSuite) TestStartFailsWithoutPanicking() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	// act
+	suite.logger = New().WithLogfile("/proc/nope/app.log").WithOutputs(OutputFile)
+	fileErr := suite.logger.Start()
+	invalid := New().WithOutputs(OutputStdout).WithRedaction(logapi.RedactionConfig{Mode: logapi.RedactMode(5)})
+	redactionErr := invalid.Start()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.ErrorContains(suite.T(), fileErr, "failed to open log file")
+	assert.ErrorContains(suite.T(), redactionErr, "invalid redaction")
+	assert.Nil(suite.T(), invalid.Shutdown())
+}
+
+func (suite *ZapLogTestSuite) TestWith() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithPort(GetFreePort())
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	child := suite.logger.With("request_id", "42")
+	child.Info("child message", "key", "value")
+	suite.logger.Info("parent message")
+	child.Sync()
+
+	childLine := findLogLine(suite.tempLogFile.Name(), "child message")
+	parentLine := findLogLine(suite.tempLogFile.Name(), "parent message")
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.NotEmpty(suite.T(), parentLine)
+	assert.Contains(suite.T(), childLine, `"request_id":"42"`)
+	assert.Contains(suite.T(), childLine, `"key":"value"`)
+	assert.NotContains(suite.T(), parentLine, `"request_id"`)
+	assert.False(suite.T(), child.(*LoggerImpl).IsShutdown())
+}
+
+func (suite *ZapLogTestSuite) TestNamedLoggerLevels() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithPort(GetFreePort())
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	db := suite.logger.Named("db")
+	pool := db.Named("pool")
+	conn := pool.Named("conn")
+
+	// act
+	recorder := httptest.NewRecorder()
+	suite.logger.logLevelHandler(recorder, httptest.NewRequest(http.MethodPost, LogServerURI+"?logger=db.pool&level=debug", nil))
+
+	db.Debug("db debug")
+	pool.Debug("pool debug")
+	conn.Debug("conn debug")
+	suite.logger.Debug("root debug")
+	suite.logger.Sync()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), zapcore.DebugLevel, suite.logger.Level("db.pool.conn"))
+	assert.Equal(suite.T(), zapcore.InfoLevel, suite.logger.Level("db"))
+	assert.Empty(suite.T(), findLogLine(suite.tempLogFile.Name(), "db debug"))
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "pool debug"), `"logger":"db.pool"`)
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "conn debug"), `"logger":"db.pool.conn"`)
+	assert.Empty(suite.T(), findLogLine(suite.tempLogFile.Name(), "root debug"))
+
+	// once reset, the named logger inherits the global level again
+	suite.logger.ResetLevel("db.pool")
+	assert.Equal(suite.T(), zapcore.InfoLevel, suite.logger.Level("db.pool.conn"))
+}
+
+func (suite *ZapLogTestSuite) TestSlogHandler() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithPort(GetFreePort())
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	l := slog.New(suite.logger.SlogHandler())
+
+	// act
+	l.WithGroup("request").With("id", 7).Info("slog message", "status", 200, slog.Group("user", "name", "bob"))
+	l.WithGroup("empty").Info("slog without attrs")
+	l.Debug("slog debug")
+	suite.logger.Sync()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	line := findLogLine(suite.tempLogFile.Name(), "slog message")
+	assert.Contains(suite.T(), line, `"request":{"id":7,"status":200,"user":{"name":"bob"}}`)
+	assert.Contains(suite.T(), line, `"caller":"zapLogger/zap_test.go:`)
+	assert.NotContains(suite.T(), findLogLine(suite.tempLogFile.Name(), "slog without attrs"), `"empty"`)
+	assert.Empty(suite.T(), findLogLine(suite.tempLogFile.Name(), "slog debug"))
+}
+
+func (suite *ZapLogTestSuite) TestReconfigureWhileLogging() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+	newLogFile := filepath.Join(suite.tempDir, "new", "app.log")
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort(GetFreePort())
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	const writers, records = 4, 500
+
+	// act
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < records; i++ {
+				suite.logger.Info("record", "id", strconv.Itoa(w*records+i))
+			}
+		}(w)
+	}
+
+	reconfigureErr := suite.logger.Reconfigure(Settings{
+		LogFile: newLogFile,
+		Level:   zapcore.InfoLevel,
+		Outputs: []string{OutputFile},
+	})
+	wg.Wait()
+	suite.logger.Sync()
+
+	// assert: every record is written exactly once, to either file
+	seen := make(map[string]int)
+	for _, file := range []string{suite.tempLogFile.Name(), newLogFile} {
+		content, readErr := os.ReadFile(file)
+		assert.Nil(suite.T(), readErr)
+		for _, line := range strings.Split(string(content), "\n") {
+			if i := strings.Index(line, `"id":"`); i >= 0 {
+				seen[strings.SplitN(line[i+6:], `"`, 2)[0]]++
+			}
+		}
+	}
+
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), reconfigureErr)
+	assert.Equal(suite.T(), writers*records, len(seen))
+	for id, count := range seen {
+		assert.Equal(suite.T(), 1, count, "record %s", id)
+	}
+	assert.Equal(suite.T(), newLogFile, suite.logger.logFile)
+}
+
+func (suite *ZapLogTestSuite) TestReconfigureFailureKeepsConfiguration() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort(GetFreePort())
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	// act: the parent of the new log file is a file, so the directory can't be created
+	reconfigureErr := suite.logger.Reconfigure(Settings{
+		LogFile: filepath.Join(suite.tempLogFile.Name(), "app.log"),
+		Level:   zapcore.DebugLevel,
+		Outputs: []string{OutputFile},
+	})
+	suite.logger.Info("still there")
+	suite.logger.Sync()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.NotNil(suite.T(), reconfigureErr)
+	assert.Equal(suite.T(), suite.tempLogFile.Name(), suite.logger.logFile)
+	assert.Equal(suite.T(), zapcore.InfoLevel, suite.logger.Level(""))
+	assert.NotEmpty(suite.T(), findLogLine(suite.tempLogFile.Name(), "still there"))
+}
+
+func (suite *ZapLogTestSuite) TestCaller() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort("")
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	// act
+	_, _, line, _ := runtime.Caller(0)
+	suite.logger.Info("direct")
+	suite.logger.With("key", "value").Named("child").Info("child")
+	suite.logger.WithCallerSkip(0).Info("caller skip")
+	suite.logger.Sync()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "direct"), fmt.Sprintf(`"caller":"zapLogger/zap_test.go:%d"`, line+1))
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "child"), fmt.Sprintf(`"caller":"zapLogger/zap_test.go:%d"`, line+2))
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "caller skip"), fmt.Sprintf(`"caller":"zapLogger/zap_test.go:%d"`, line+3))
+}
+
+func (suite *ZapLogTestSuite) TestTraceLevel() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort("")
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	// act
+	suite.logger.Trace("hidden trace")
+	recorder := httptest.NewRecorder()
+	suite.logger.logLevelHandler(recorder, httptest.NewRequest(http.MethodPost, LogServerURI+"?level=trace", nil))
+	suite.logger.Trace("visible trace", "key", "value")
+	suite.logger.Sync()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), TraceLevel, suite.logger.Level(""))
+	assert.Empty(suite.T(), findLogLine(suite.tempLogFile.Name(), "hidden trace"))
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "visible trace"), `"level":"trace"`)
+}
+
+func (suite *ZapLogTestSuite) TestFatalFlushesAndExits() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	exitCode := -1
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort("").
+		WithExitFunc(func(code int) { exitCode = code })
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	// act
+	suite.logger.Named("db").Fatal("fatal error", "key", "value")
+
+	// assert: the record is in the file without calling Sync
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, exitCode)
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "fatal error"), `"level":"fatal"`)
+}
+
+func (suite *ZapLogTestSuite) TestPanicAndDPanic() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort("")
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	// act & assert
+	assert.PanicsWithValue(suite.T(), "panic error", func() { suite.logger.Panic("panic error") })
+	assert.NotPanics(suite.T(), func() { suite.logger.DPanic("dpanic error") })
+	suite.logger.Sync()
+
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "panic error"), `"level":"panic"`)
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "dpanic error"), `"level":"dpanic"`)
+
+	development := New().WithOutputs(OutputStderr).WithPort("").WithDevelopment(true)
+	assert.Nil(suite.T(), development.Start())
+	defer development.Shutdown()
+	assert.Panics(suite.T(), func() { development.DPanic("dpanic in development") })
+}
+
+func (suite *ZapLogTestSuite) TestPrintfAndLazyVariants() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort("")
+	assert.Nil(suite.T(), suite.logger.Start())
+	db := suite.logger.Named("db")
+	suite.logger.SetLevel("db", zapcore.DebugLevel)
+
+	built := 0
+	payload := func() (string, []any) {
+		built++
+		return "lazy debug", []any{"payload", "expensive"}
+	}
+
+	// act
+	_, _, line, _ := runtime.Caller(0)
+	suite.logger.Infof("formatted %d/%s", 42, "info")
+	suite.logger.DebugFn(payload)
+	suite.logger.Debugf("hidden %s", "debug")
+	db.DebugFn(payload)
+	suite.logger.Sync()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, built)
+	assert.False(suite.T(), suite.logger.Enabled(logapi.DebugLevel))
+	assert.True(suite.T(), db.Enabled(logapi.DebugLevel))
+	assert.False(suite.T(), db.Enabled(logapi.TraceLevel))
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "formatted 42/info"), fmt.Sprintf(`"caller":"zapLogger/zap_test.go:%d"`, line+1))
+	assert.Empty(suite.T(), findLogLine(suite.tempLogFile.Name(), "hidden"))
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "lazy debug"), `"logger":"db"`)
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "lazy debug"), `"payload":"expensive"`)
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "lazy debug"), fmt.Sprintf(`"caller":"zapLogger/zap_test.go:%d"`, line+4))
+}
+
+func (suite *ZapLogTestSuite) TestMessageTemplates() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort("")
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	// act
+	suite.logger.With("user", "ignored").Info("User {user} bought {count} items", "user", "bob", "count", 3)
+	suite.logger.Warn("Missing {argument}")
+	suite.logger.Info("Escaped {{user}}", "user", "bob")
+	suite.logger.Sync()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "User bob bought 3 items"), `"user":"bob","count":3,"message_template":"User {user} bought {count} items"`)
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "Missing {argument}"), `"message_template":"Missing {argument}"`)
+	escaped := findLogLine(suite.tempLogFile.Name(), "Escaped {{user}}")
+	assert.NotEmpty(suite.T(), escaped)
+	assert.NotContains(suite.T(), escaped, "message_template")
+}
+
+type point struct{ x, y int }
+
+func (p point) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt("x", p.x)
+	enc.AddInt("y", p.y)
+	return nil
+}
+
+type secret string
+
+func (s secret) LogValue() slog.Value {
+	return slog.StringValue("***")
+}
+
+type ip [4]byte
+
+func (i ip) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d", i[0], i[1], i[2], i[3])
+}
+
+func (suite *ZapLogTestSuite) TestFieldConversion() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+	suite.logger = New().WithLogfile(suite.tempLogFile.Name()).WithOutputs(OutputFile).WithPort("")
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	enc := zapcore.NewMapObjectEncoder()
+
+	// act
+	for _, f := range toZapFields(suite.logger.normalize([]any{
+		"int8", int8(-8), "uint16", uint16(16), "uint64", uint64(64), "float32", float32(1.5),
+		"at", at, "timeout", 1500 * time.Millisecond, "text", []byte("text"), "binary", []byte{0xff},
+		"point", point{1, 2}, "password", secret("hunter2"), "addr", ip{10, 0, 0, 1},
+		"cause", fmt.Errorf("wrapped: %w", os.ErrNotExist), os.ErrClosed,
+	})...) {
+		f.AddTo(enc)
+	}
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int8(-8), enc.Fields["int8"])
+	assert.Equal(suite.T(), uint16(16), enc.Fields["uint16"])
+	assert.Equal(suite.T(), uint64(64), enc.Fields["uint64"])
+	assert.Equal(suite.T(), float32(1.5), enc.Fields["float32"])
+	assert.Equal(suite.T(), at, enc.Fields["at"])
+	assert.Equal(suite.T(), 1500*time.Millisecond, enc.Fields["timeout"])
+	assert.Equal(suite.T(), "text", enc.Fields["text"])
+	assert.Equal(suite.T(), []byte{0xff}, enc.Fields["binary"])
+	assert.Equal(suite.T(), map[string]any{"x": 1, "y": 2}, enc.Fields["point"])
+	assert.Equal(suite.T(), "***", enc.Fields["password"])
+	assert.Equal(suite.T(), "10.0.0.1", enc.Fields["addr"])
+	assert.Equal(suite.T(), "wrapped: file does not exist", enc.Fields["cause"])
+	assert.Equal(suite.T(), "file already closed", enc.Fields["error"])
+}
+
+func (suite *ZapLogTestSuite) TestMalformedArgs() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort("")
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	// act
+	suite.logger.Named("db").Warn("odd", "key", "value", "orphan")
+	suite.logger.Info("bad key", 42, "value")
+	suite.logger.Sync()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "odd"), `"key":"value","!EXTRA":"orphan"`)
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "bad key"), `"!BADKEY":42,"!EXTRA":"value"`)
+	assert.Equal(suite.T(), uint64(3), suite.logger.MalformedArgs())
+}
+
+func (suite *ZapLogTestSuite) TestTypedFields() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort("")
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	// act
+	_, _, line, _ := runtime.Caller(0)
+	suite.logger.With("key", "value").InfoF("typed", logapi.String("path", "/users"), logapi.Int("status", 200))
+	suite.logger.TraceF("hidden", logapi.Bool("trace", true))
+	suite.logger.Sync()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "typed"), fmt.Sprintf(`"caller":"zapLogger/zap_test.go:%d"`, line+1))
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "typed"), `"key":"value","path":"/users","status":200`)
+	assert.Empty(suite.T(), findLogLine(suite.tempLogFile.Name(), "hidden"))
+}
+
 func TestRouterTestSuite(t *testing.T) {
 	suite.Run(t, new(ZapLogTestSuite))
 }
+
+type user struct {
+	Name     string            `json:"name"`
+	Password string            `json:"password"`
+	Contacts map[string]string `json:"contacts"`
+}
+
+type apiKey string
+
+func (apiKey) Redacted() any {
+	return "key-****"
+}
+
+func (suite *ZapLogTestSuite) TestRedaction() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort("").
+		WithRedaction(logapi.DefaultRedaction())
+	assert.Nil(suite.T(), suite.logger.Start())
+
+	// act
+	suite.logger.With("authorization", "Bearer abc").Info("login of bob@example.com",
+		"user", user{Name: "bob", Password: "hunter2", Contacts: map[string]string{"work": "bob@corp.example.com"}},
+		"key", apiKey("sk_live_123"),
+		"card", "4111111111111111",
+		"status", 200,
+	)
+	suite.logger.InfoF("typed", logapi.String("session_token", "s3cr3t"), logapi.Any("payload", map[string]any{"secret": 1}))
+	suite.logger.Info("login with {password}", "password", "hunter2")
+	suite.logger.Sync()
+
+	// assert
+	content, readErr := os.ReadFile(suite.tempLogFile.Name())
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), readErr)
+	assert.Contains(suite.T(), string(content), `"msg":"login of ***","authorization":"***","user":{"contacts":{"work":"***"},"name":"bob","password":"***"},"key":"key-****","card":"***","status":200`)
+	assert.Contains(suite.T(), string(content), `"session_token":"***","payload":{"secret":"***"}`)
+	assert.Contains(suite.T(), string(content), `"msg":"login with ***","password":"***"`)
+	for _, secret := range []string{"Bearer abc", "hunter2", "bob@", "sk_live_123", "4111", "s3cr3t"} {
+		assert.NotContains(suite.T(), string(content), secret)
+	}
+}
+
+func (suite *ZapLogTestSuite) TestRedactionOfTheContextAfterReconfigure() {
+	var err error
+	suite.tempLogFile, suite.tempDir, err = createTempFile()
+
+	suite.logger = New().
+		WithLogfile(suite.tempLogFile.Name()).
+		WithOutputs(OutputFile).
+		WithPort("")
+	assert.Nil(suite.T(), suite.logger.Start())
+	child := suite.logger.With("password", "hunter2")
+	child.Info("before")
+
+	// act
+	reconfigureErr := suite.logger.Reconfigure(Settings{
+		LogFile:   suite.tempLogFile.Name(),
+		Outputs:   []string{OutputFile},
+		Redaction: logapi.DefaultRedaction(),
+	})
+	child.Info("after")
+	suite.logger.Sync()
+
+	// assert
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), reconfigureErr)
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "before"), `"password":"hunter2"`)
+	assert.Contains(suite.T(), findLogLine(suite.tempLogFile.Name(), "after"), `"password":"***"`)
+}